@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/vin-jex/job-orchestrator/internal/api"
 	"github.com/vin-jex/job-orchestrator/internal/observability"
 	"github.com/vin-jex/job-orchestrator/internal/store"
+	"github.com/vin-jex/job-orchestrator/internal/webhooks"
 
 	_ "github.com/vin-jex/job-orchestrator/docs"
 )
@@ -53,7 +55,57 @@ func main() {
 	}
 	defer storeLayer.Close()
 
-	server := api.NewServer(storeLayer, logger)
+	config := api.Config{}
+
+	if raw := os.Getenv("IDEMPOTENCY_KEY_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("invalid IDEMPOTENCY_KEY_RETENTION: ", err)
+		}
+		config.IdempotencyKeyRetention = retention
+	}
+
+	if raw := os.Getenv("JOB_RESULT_MAX_BYTES"); raw != "" {
+		maxBytes, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_RESULT_MAX_BYTES: ", err)
+		}
+		config.JobResultMaxBytes = maxBytes
+	}
+
+	if raw := os.Getenv("JOB_RESULT_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_RESULT_RETENTION: ", err)
+		}
+		config.JobResultRetention = retention
+	}
+
+	webhookConfig := webhooks.Config{}
+
+	// WEBHOOK_ALLOWED_NETWORKS is a comma-separated list of CIDR prefixes of
+	// internal networks that webhooks may reach; unset means none.
+	if raw := os.Getenv("WEBHOOK_ALLOWED_NETWORKS"); raw != "" {
+		allowed, err := webhooks.ParseAllowedNetworks(raw)
+		if err != nil {
+			log.Fatal("invalid WEBHOOK_ALLOWED_NETWORKS: ", err)
+		}
+		config.WebhookAllowedNetworks = allowed
+		webhookConfig.AllowedNetworks = allowed
+	}
+
+	if raw := os.Getenv("WEBHOOK_DELIVERY_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("invalid WEBHOOK_DELIVERY_RETENTION: ", err)
+		}
+		webhookConfig.DeliveryRetention = retention
+	}
+
+	server := api.NewServer(storeLayer, logger, config)
+
+	go server.RunEventListener(ctx)
+	go webhooks.NewDispatcher(storeLayer, logger, webhookConfig).Run(ctx)
 
 	httpServer := &http.Server{
 		Addr:         ":8080",
@@ -18,7 +18,10 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -52,7 +55,54 @@ func main() {
 	}
 	defer storeLayer.Close()
 
-	w := worker.New(workerID, 4, storeLayer, logger)
+	config := worker.Config{}
+
+	if raw := os.Getenv("JOB_RESULT_MAX_BYTES"); raw != "" {
+		maxBytes, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_RESULT_MAX_BYTES: ", err)
+		}
+		config.ResultMaxBytes = maxBytes
+	}
+
+	if raw := os.Getenv("JOB_RESULT_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_RESULT_RETENTION: ", err)
+		}
+		config.ResultRetention = retention
+	}
+
+	if raw := os.Getenv("JOB_LOG_MAX_BYTES"); raw != "" {
+		maxBytes, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_LOG_MAX_BYTES: ", err)
+		}
+		config.LogMaxBytes = maxBytes
+	}
+
+	if raw := os.Getenv("JOB_LOG_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatal("invalid JOB_LOG_RETENTION: ", err)
+		}
+		config.LogRetention = retention
+	}
+
+	w := worker.New(workerID, 4, storeLayer, logger, config)
+
+	// Built-in handler that completes immediately; useful for smoke tests.
+	w.Register("noop", worker.HandlerFunc(func(context.Context, *worker.Job) error {
+		return nil
+	}))
+
+	// WORKER_QUEUES is a comma-separated list of queues to take jobs from;
+	// unset means every queue.
+	for _, queue := range strings.Split(os.Getenv("WORKER_QUEUES"), ",") {
+		if queue = strings.TrimSpace(queue); queue != "" {
+			w.Subscribe(queue)
+		}
+	}
 
 	// Render keepalive HTTP server (infrastructure hack)
 	go func() {
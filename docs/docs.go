@@ -95,19 +95,72 @@ const docTemplate = `{
         },
         "/internal/jobs/recover": {
             "post": {
-                "description": "Reclaim jobs whose leases have expired",
+                "description": "Trigger asynchronous recovery of expired job leases",
+                "tags": [
+                    "Internal-Scheduler"
+                ],
+                "summary": "Recover expired leases",
+                "responses": {
+                    "202": {
+                        "description": "Recovery triggered"
+                    }
+                }
+            }
+        },
+        "/internal/jobs/{jobID}/complete": {
+            "post": {
+                "description": "Mark a RUNNING job as COMPLETED",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Internal-Scheduler"
+                    "Internal-Worker"
+                ],
+                "summary": "Complete job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lease fencing token and optional result",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CompleteJobRequest"
+                        }
+                    }
                 ],
-                "summary": "Recover expired leases",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.RecoverLeasesResponse"
+                            "$ref": "#/definitions/api.CompleteJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -119,16 +172,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/jobs/{jobID}/complete": {
+        "/internal/jobs/{jobID}/fail": {
             "post": {
-                "description": "Mark a RUNNING job as COMPLETED",
+                "description": "Record a failed attempt of a RUNNING job. The response state is PENDING when the job will be retried and FAILED otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Internal-Worker"
                 ],
-                "summary": "Complete job",
+                "summary": "Fail job",
                 "parameters": [
                     {
                         "type": "string",
@@ -136,13 +192,22 @@ const docTemplate = `{
                         "name": "jobID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Failure details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FailJobRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.CompleteJobResponse"
+                            "$ref": "#/definitions/api.FailJobResponse"
                         }
                     },
                     "400": {
@@ -166,9 +231,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/internal/jobs/{jobID}/fail": {
+        "/internal/jobs/{jobID}/lease/renew": {
             "post": {
-                "description": "Mark a RUNNING job as FAILED with retry semantics",
+                "description": "Extend the lease of a SCHEDULED or RUNNING job held by the caller",
                 "consumes": [
                     "application/json"
                 ],
@@ -178,7 +243,7 @@ const docTemplate = `{
                 "tags": [
                     "Internal-Worker"
                 ],
-                "summary": "Fail job",
+                "summary": "Renew job lease",
                 "parameters": [
                     {
                         "type": "string",
@@ -188,12 +253,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Failure details",
+                        "description": "Renewal request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.FailJobRequest"
+                            "$ref": "#/definitions/api.RenewLeaseRequest"
                         }
                     }
                 ],
@@ -201,7 +266,66 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.FailJobResponse"
+                            "$ref": "#/definitions/api.RenewLeaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/internal/jobs/{jobID}/progress": {
+            "post": {
+                "description": "Replace the progress of a RUNNING job and extend its lease. Only the current lease holder may report.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Internal-Worker"
+                ],
+                "summary": "Report job progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lease fencing token and progress",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportJobProgressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ReportJobProgressResponse"
                         }
                     },
                     "400": {
@@ -228,6 +352,9 @@ const docTemplate = `{
         "/internal/jobs/{jobID}/start": {
             "post": {
                 "description": "Transition a job from SCHEDULED to RUNNING",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
@@ -242,6 +369,15 @@ const docTemplate = `{
                         "name": "jobID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Lease fencing token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.StartJobRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -307,6 +443,47 @@ const docTemplate = `{
                 }
             }
         },
+        "/internal/workers/{workerID}/recover": {
+            "post": {
+                "description": "Release every lease claimed by a worker so its jobs can be rescheduled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Internal-Scheduler"
+                ],
+                "summary": "Recover worker leases",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Worker ID",
+                        "name": "workerID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RecoverLeasesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/metrics": {
             "get": {
                 "description": "Exposes service metrics in Prometheus format",
@@ -353,26 +530,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/jobs": {
+        "/v1/dead-letters": {
             "get": {
-                "description": "List jobs with optional state filtering and limit",
+                "description": "List jobs that exhausted their attempts or failed with a non-retryable error, newest first, with the attempts and errors of each failed job",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jobs"
+                    "DeadLetters"
                 ],
-                "summary": "List jobs",
+                "summary": "List dead letters",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by job state",
-                        "name": "state",
+                        "description": "Filter by job type",
+                        "name": "job_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by queue",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Filter by whether the dead letter was replayed",
+                        "name": "replayed",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Maximum number of jobs (default 100)",
+                        "description": "Maximum number of dead letters (default 100)",
                         "name": "limit",
                         "in": "query"
                     }
@@ -381,7 +570,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.ListJobsResponse"
+                            "$ref": "#/definitions/api.ListDeadLettersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "500": {
@@ -391,9 +586,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/v1/dead-letters/purge": {
             "post": {
-                "description": "Create a job in the PENDING state",
+                "description": "Remove the selected dead letters. The failed jobs themselves are kept.",
                 "consumes": [
                     "application/json"
                 ],
@@ -401,25 +598,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Jobs"
+                    "DeadLetters"
                 ],
-                "summary": "Create a new job",
+                "summary": "Purge dead letters",
                 "parameters": [
                     {
-                        "description": "Job creation payload",
+                        "description": "Dead letters to purge",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.CreateJobRequest"
+                            "$ref": "#/definitions/api.DeadLetterSelector"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.CreateJobResponse"
+                            "$ref": "#/definitions/api.PurgeDeadLettersResponse"
                         }
                     },
                     "400": {
@@ -437,30 +634,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/jobs/{jobID}": {
-            "get": {
-                "description": "Fetch the authoritative state and metadata of a job",
+        "/v1/dead-letters/replay": {
+            "post": {
+                "description": "Create a new PENDING job for every selected dead letter that was not replayed yet, oldest first and at most limit of them. The failed job stays FAILED; the new job links back to it through replay_of_job_id. Dead letters whose unique_key is held by an unfinished job are skipped. remaining counts the selected dead letters still to replay.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Jobs"
+                    "DeadLetters"
                 ],
-                "summary": "Get job details",
+                "summary": "Replay dead letters",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Job ID",
-                        "name": "jobID",
-                        "in": "path",
-                        "required": true
+                        "description": "Dead letters to replay",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeadLetterSelector"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.JobResponse"
+                            "$ref": "#/definitions/api.ReplayDeadLettersResponse"
                         }
                     },
                     "400": {
@@ -469,12 +671,6 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -484,25 +680,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/jobs/{jobID}/cancel": {
-            "post": {
-                "description": "Cancel a job in any non-terminal state. Cancellation is idempotent.",
+        "/v1/dead-letters/{deadLetterID}": {
+            "get": {
+                "description": "Fetch a dead letter with the failed job and all of its attempts and errors",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Jobs"
+                    "DeadLetters"
                 ],
-                "summary": "Cancel a job",
+                "summary": "Get a dead letter",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Dead letter ID",
+                        "name": "deadLetterID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.DeadLetterResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -510,8 +712,8 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
@@ -524,48 +726,2241 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/v1/events": {
+            "get": {
+                "description": "Return job state transitions after the given cursor, oldest first. The feed is gap-free: resuming from the last next_cursor seen never skips an event. With wait, the request is held open until an event arrives or the wait elapses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Events"
+                ],
+                "summary": "Job state change feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by a previous call; omit to start at the beginning",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of jobs of this type",
+                        "name": "job_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events of jobs in this queue",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to wait for events when none are available (max 30)",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs": {
+            "get": {
+                "description": "List jobs with optional state, queue and priority filtering, sorting and limit",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter by job state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by queue",
+                        "name": "queue",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only PENDING jobs whose next run is in the future",
+                        "name": "delayed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only jobs with at least this priority",
+                        "name": "min_priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only jobs with at most this priority",
+                        "name": "max_priority",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: created_at (default, newest first) or priority (highest first)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of jobs (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListJobsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a job in the PENDING state. Set run_at or delay_seconds to defer its first run. Jobs with a higher priority are leased first. Jobs without a queue go to the default queue.\nWith an Idempotency-Key header, retrying the same request returns the job created by the first one instead of creating another.\nWith a unique_key, at most one unfinished job holds the key; on_conflict chooses whether a duplicate is rejected (reject, the default), answered with the existing job (return_existing), or replaces the existing job if it is still PENDING (replace).\nWith a concurrency_key, at most concurrency_limit (default 1) jobs sharing the key are scheduled or running at once.\nWith wait, the response is held until the job reaches a terminal state or the wait passes, and includes the job.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Create a new job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-chosen key that makes retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Job creation payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateJobRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for the job to finish, as a duration (30s) or seconds (max 60s)",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Existing job with the same unique_key",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateJobResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Idempotency-Key reused with a different request, or unique_key taken",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/watch": {
+            "get": {
+                "description": "Stream state changes of all jobs, optionally narrowed to a job type or queue, as Server-Sent \"transition\" events. Changes made while the client is disconnected are not replayed; use /v1/events to catch up.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Watch jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only jobs of this type",
+                        "name": "job_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only jobs in this queue",
+                        "name": "queue",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.JobTransitionResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}": {
+            "get": {
+                "description": "Fetch the authoritative state and metadata of a job",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get job details",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/attempts": {
+            "get": {
+                "description": "List every execution attempt of a job, oldest first, with the worker and lease holder that ran it and how it ended",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List job attempts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListJobAttemptsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/cancel": {
+            "post": {
+                "description": "Cancel a job in any non-terminal state. Cancellation is idempotent.",
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Cancel a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/events": {
+            "get": {
+                "description": "Return the audit log of a job, oldest first: its creation and every state transition, with the actor that caused it and the reason",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List job events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListJobEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/logs": {
+            "get": {
+                "description": "Return the log output written by a job's handler, oldest first, optionally for a single attempt. With follow=true the logs are streamed as Server-Sent \"log\" events, including output written while the job runs, and the stream ends once the job reaches a terminal state.",
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Job logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only logs of this attempt, numbered as in /v1/jobs/{jobID}/attempts",
+                        "name": "attempt",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only chunks with a greater id, e.g. next_after of a previous call",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of chunks (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream logs until the job finishes",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListJobLogsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/result": {
+            "get": {
+                "description": "Get the result a job reported when it completed, until the result's retention period ends",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Get job result",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.JobResultResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/wait": {
+            "get": {
+                "description": "Block until the job reaches a terminal state or the timeout passes, then return the job. A job that is still unfinished when the timeout passes is returned as is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Wait for a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait, as a duration (30s) or seconds (default 30s, max 60s)",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.JobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{jobID}/watch": {
+            "get": {
+                "description": "Stream the job's state changes as Server-Sent Events. The stream starts with a \"state\" event carrying the current job, followed by a \"transition\" event for every state change, and ends after the job reaches a terminal state.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Watch a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.JobTransitionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/queues": {
+            "get": {
+                "description": "List configured queues and queues with unfinished jobs, with their concurrency limit and load",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queues"
+                ],
+                "summary": "List queues",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListQueuesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/queues/{queue}": {
+            "put": {
+                "description": "Set the maximum number of scheduled and running jobs of a queue. A null max_concurrency removes the limit.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queues"
+                ],
+                "summary": "Configure a queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Queue name",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Queue settings",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetQueueRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/schedules": {
+            "get": {
+                "description": "List recurring schedules ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "List schedules",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of schedules (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListSchedulesResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a recurring schedule that creates a job on every cron tick",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Create a schedule",
+                "parameters": [
+                    {
+                        "description": "Schedule definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/schedules/{scheduleID}": {
+            "get": {
+                "description": "Fetch a recurring schedule and its next tick",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Get a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a schedule definition. The next tick is recomputed from now, so missed ticks are not caught up.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Update a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Schedule definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a schedule. Jobs it already created are kept.",
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Delete a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/schedules/{scheduleID}/pause": {
+            "post": {
+                "description": "Stop a schedule from creating jobs until it is resumed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Pause a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/schedules/{scheduleID}/resume": {
+            "post": {
+                "description": "Resume a paused schedule from its next tick. Ticks missed while paused are not caught up.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Schedules"
+                ],
+                "summary": "Resume a schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Schedule ID",
+                        "name": "scheduleID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ScheduleResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "description": "List webhook subscriptions in creation order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListWebhooksResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe a URL to job state changes, optionally filtered by job type, queue and target states. Every matching event is POSTed as JSON, signed in the X-Webhook-Signature header with the HMAC-SHA256 of \"\u003cX-Webhook-Timestamp\u003e.\u003cbody\u003e\" keyed with the secret. Failed deliveries are retried with backoff. The secret is only returned here. URLs resolving to loopback, private, link-local or other special-purpose addresses are rejected unless allowed by WEBHOOK_ALLOWED_NETWORKS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create a webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook subscription",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookID}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a webhook subscription along with its delivery log",
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "description": "Return the delivery log of a webhook, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ListWebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver": {
+            "post": {
+                "description": "Queue a delivery to be sent again right away with a fresh retry budget, whatever its current state",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Redeliver a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api.WebhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workflows": {
+            "post": {
+                "description": "Create a set of jobs connected by depends_on edges. Jobs with dependencies stay BLOCKED until every job they depend on has COMPLETED. If a dependency fails or is cancelled, its blocked dependents are failed (failure_policy FAIL) or cancelled (CANCEL, the default).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Create a workflow",
+                "parameters": [
+                    {
+                        "description": "Workflow definition",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWorkflowRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateWorkflowResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/workflows/{workflowID}": {
+            "get": {
+                "description": "Fetch a workflow with the state and dependencies of each of its jobs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workflows"
+                ],
+                "summary": "Get a workflow",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workflow ID",
+                        "name": "workflowID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.WorkflowResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
         "api.AcquireLeaseRequest": {
             "type": "object",
             "properties": {
-                "lease_duration_seconds": {
+                "lease_duration_seconds": {
+                    "type": "integer"
+                },
+                "scheduler_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AcquireLeaseResponse": {
+            "type": "object",
+            "properties": {
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "lease_expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CompleteJobRequest": {
+            "type": "object",
+            "properties": {
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "result": {}
+            }
+        },
+        "api.CompleteJobResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateJobRequest": {
+            "type": "object",
+            "properties": {
+                "concurrency_key": {
+                    "type": "string"
+                },
+                "concurrency_limit": {
+                    "type": "integer"
+                },
+                "delay_seconds": {
+                    "type": "integer"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "on_conflict": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "retry_policy": {
+                    "$ref": "#/definitions/api.RetryPolicyRequest"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unique_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateJobResponse": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/api.JobResponse"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "job_type": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWorkflowJobResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWorkflowRequest": {
+            "type": "object",
+            "properties": {
+                "failure_policy": {
+                    "type": "string"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WorkflowJobRequest"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CreateWorkflowResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.CreateWorkflowJobResponse"
+                    }
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.DeadLetterResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobAttemptResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "dead_letter_id": {
+                    "type": "string"
+                },
+                "job": {
+                    "$ref": "#/definitions/api.JobResponse"
+                },
+                "replayed_at": {
+                    "type": "string"
+                },
+                "replayed_job_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.DeadLetterSelector": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "boolean"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.EventResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "type": "string"
+                },
+                "from_state": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.FailJobRequest": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "retryable": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.FailJobResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.JobAttemptResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "type": "string"
+                },
+                "retryable": {
+                    "type": "boolean"
+                },
+                "scheduler_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "worker_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.JobEventResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_state": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.JobLogResponse": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.JobProgressResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "integer"
+                },
+                "stage": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.JobResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "concurrency_key": {
+                    "type": "string"
+                },
+                "concurrency_limit": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_attempt": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "$ref": "#/definitions/api.JobProgressResponse"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "replay_of_job_id": {
+                    "type": "string"
+                },
+                "retry_policy": {
+                    "$ref": "#/definitions/api.RetryPolicyResponse"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unique_key": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "worker_id": {
+                    "type": "string"
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.JobResultResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "size_bytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.JobTransitionResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "from_state": {
+                    "type": "string"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "to_state": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ListDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "dead_letters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.DeadLetterResponse"
+                    }
+                }
+            }
+        },
+        "api.ListEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.EventResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ListJobAttemptsResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobAttemptResponse"
+                    }
+                },
+                "job_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ListJobEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobEventResponse"
+                    }
+                },
+                "job_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ListJobLogsResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "logs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobLogResponse"
+                    }
+                },
+                "next_after": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ListJobsResponse": {
+            "type": "object",
+            "properties": {
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.JobResponse"
+                    }
+                }
+            }
+        },
+        "api.ListQueuesResponse": {
+            "type": "object",
+            "properties": {
+                "queues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.QueueResponse"
+                    }
+                }
+            }
+        },
+        "api.ListSchedulesResponse": {
+            "type": "object",
+            "properties": {
+                "schedules": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ScheduleResponse"
+                    }
+                }
+            }
+        },
+        "api.ListWebhookDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookDeliveryResponse"
+                    }
+                }
+            }
+        },
+        "api.ListWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WebhookResponse"
+                    }
+                }
+            }
+        },
+        "api.PurgeDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "purged": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.QueueResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "integer"
+                },
+                "max_concurrency": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RecoverLeasesResponse": {
+            "type": "object",
+            "properties": {
+                "recovered_job_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.RenewLeaseRequest": {
+            "type": "object",
+            "properties": {
+                "extension_seconds": {
+                    "type": "integer"
+                },
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "holder_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RenewLeaseResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "string"
+                },
+                "lease_expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ReplayDeadLettersResponse": {
+            "type": "object",
+            "properties": {
+                "remaining": {
                     "type": "integer"
                 },
-                "scheduler_id": {
-                    "type": "string"
+                "replayed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ReplayedDeadLetterResponse"
+                    }
                 }
             }
         },
-        "api.AcquireLeaseResponse": {
+        "api.ReplayedDeadLetterResponse": {
             "type": "object",
             "properties": {
+                "dead_letter_id": {
+                    "type": "string"
+                },
                 "job_id": {
                     "type": "string"
+                }
+            }
+        },
+        "api.ReportJobProgressRequest": {
+            "type": "object",
+            "properties": {
+                "fencing_token": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
                 },
-                "lease_expires_at": {
+                "percent": {
+                    "type": "integer"
+                },
+                "stage": {
                     "type": "string"
                 }
             }
         },
-        "api.CompleteJobResponse": {
+        "api.ReportJobProgressResponse": {
             "type": "object",
             "properties": {
                 "job_id": {
                     "type": "string"
                 },
-                "state": {
+                "lease_expires_at": {
                     "type": "string"
                 }
             }
         },
-        "api.CreateJobRequest": {
+        "api.RetryPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "initial_delay_seconds": {
+                    "type": "integer"
+                },
+                "jitter": {
+                    "type": "number"
+                },
+                "max_delay_seconds": {
+                    "type": "integer"
+                },
+                "multiplier": {
+                    "type": "number"
+                }
+            }
+        },
+        "api.RetryPolicyResponse": {
+            "type": "object",
+            "properties": {
+                "initial_delay_seconds": {
+                    "type": "integer"
+                },
+                "jitter": {
+                    "type": "number"
+                },
+                "max_delay_seconds": {
+                    "type": "integer"
+                },
+                "multiplier": {
+                    "type": "number"
+                }
+            }
+        },
+        "api.ScheduleRequest": {
             "type": "object",
             "properties": {
+                "catch_up_policy": {
+                    "type": "string"
+                },
+                "cron_expression": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
                 "max_attempts": {
                     "type": "integer"
                 },
+                "name": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
                 "payload": {
                     "type": "object",
                     "additionalProperties": {}
@@ -573,34 +2968,78 @@ const docTemplate = `{
                 "timeout_seconds": {
                     "type": "integer"
                 },
-                "type": {
+                "timezone": {
                     "type": "string"
                 }
             }
         },
-        "api.CreateJobResponse": {
+        "api.ScheduleResponse": {
             "type": "object",
             "properties": {
-                "job_id": {
+                "catch_up_policy": {
                     "type": "string"
                 },
-                "state": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cron_expression": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "last_run_at": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "api.FailJobRequest": {
+        "api.SetQueueRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
-                },
-                "retryable": {
-                    "type": "boolean"
+                "max_concurrency": {
+                    "type": "integer"
                 }
             }
         },
-        "api.FailJobResponse": {
+        "api.StartJobRequest": {
+            "type": "object",
+            "properties": {
+                "fencing_token": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.StartJobResponse": {
             "type": "object",
             "properties": {
                 "job_id": {
@@ -611,16 +3050,22 @@ const docTemplate = `{
                 }
             }
         },
-        "api.JobResponse": {
+        "api.WebhookDeliveryResponse": {
             "type": "object",
             "properties": {
-                "cancelled_at": {
-                    "type": "string"
+                "attempts": {
+                    "type": "integer"
                 },
                 "created_at": {
                     "type": "string"
                 },
-                "current_attempt": {
+                "delivered_at": {
+                    "type": "string"
+                },
+                "delivery_id": {
+                    "type": "string"
+                },
+                "event_id": {
                     "type": "integer"
                 },
                 "job_id": {
@@ -629,9 +3074,12 @@ const docTemplate = `{
                 "last_error": {
                     "type": "string"
                 },
-                "max_attempts": {
+                "last_status_code": {
                     "type": "integer"
                 },
+                "next_attempt_at": {
+                    "type": "string"
+                },
                 "payload": {
                     "type": "array",
                     "items": {
@@ -641,44 +3089,203 @@ const docTemplate = `{
                 "state": {
                     "type": "string"
                 },
-                "timeout_seconds": {
-                    "type": "integer"
-                },
                 "updated_at": {
                     "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
                 }
             }
         },
-        "api.ListJobsResponse": {
+        "api.WebhookResponse": {
             "type": "object",
             "properties": {
-                "jobs": {
+                "created_at": {
+                    "type": "string"
+                },
+                "job_type": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "states": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.JobResponse"
+                        "type": "string"
                     }
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "string"
                 }
             }
         },
-        "api.RecoverLeasesResponse": {
+        "api.WorkflowJobRequest": {
             "type": "object",
             "properties": {
-                "recovered_job_ids": {
+                "concurrency_key": {
+                    "type": "string"
+                },
+                "concurrency_limit": {
+                    "type": "integer"
+                },
+                "delay_seconds": {
+                    "type": "integer"
+                },
+                "depends_on": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "key": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "on_conflict": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "retry_policy": {
+                    "$ref": "#/definitions/api.RetryPolicyRequest"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unique_key": {
+                    "type": "string"
                 }
             }
         },
-        "api.StartJobResponse": {
+        "api.WorkflowJobResponse": {
             "type": "object",
             "properties": {
+                "cancelled_at": {
+                    "type": "string"
+                },
+                "concurrency_key": {
+                    "type": "string"
+                },
+                "concurrency_limit": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current_attempt": {
+                    "type": "integer"
+                },
+                "depends_on": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "job_id": {
                     "type": "string"
                 },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "progress": {
+                    "$ref": "#/definitions/api.JobProgressResponse"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "replay_of_job_id": {
+                    "type": "string"
+                },
+                "retry_policy": {
+                    "$ref": "#/definitions/api.RetryPolicyResponse"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "schedule_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
                 "state": {
                     "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unique_key": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "worker_id": {
+                    "type": "string"
+                },
+                "workflow_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.WorkflowResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "failure_policy": {
+                    "type": "string"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.WorkflowJobResponse"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "workflow_id": {
+                    "type": "string"
                 }
             }
         }
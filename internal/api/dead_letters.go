@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+var errEmptyDeadLetterSelector = errors.New("select dead letters by ids, job_type or queue, or set all")
+
+// deadLetterFilterFromSelector turns a replay or purge selector into a store
+// filter. An empty selector is rejected so that a bare request body cannot
+// act on every dead letter by accident.
+func deadLetterFilterFromSelector(selector DeadLetterSelector) (store.DeadLetterFilter, error) {
+	var filter store.DeadLetterFilter
+
+	for _, rawID := range selector.IDs {
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			return store.DeadLetterFilter{}, errors.New("invalid dead letter id")
+		}
+		filter.IDs = append(filter.IDs, id)
+	}
+
+	if selector.JobType != "" {
+		filter.JobType = &selector.JobType
+	}
+
+	if selector.Queue != "" {
+		filter.Queue = &selector.Queue
+	}
+
+	if len(filter.IDs) == 0 && filter.JobType == nil && filter.Queue == nil && !selector.All {
+		return store.DeadLetterFilter{}, errEmptyDeadLetterSelector
+	}
+
+	return filter, nil
+}
+
+// @Summary List dead letters
+// @Description List jobs that exhausted their attempts or failed with a non-retryable error, newest first, with the attempts and errors of each failed job
+// @Tags DeadLetters
+// @Produce json
+// @Param job_type query string false "Filter by job type"
+// @Param queue query string false "Filter by queue"
+// @Param replayed query bool false "Filter by whether the dead letter was replayed"
+// @Param limit query int false "Maximum number of dead letters (default 100)"
+// @Success 200 {object} ListDeadLettersResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/dead-letters [get]
+func (s *Server) handleListDeadLetters(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	query := request.URL.Query()
+
+	filter := store.DeadLetterFilter{
+		Limit: 100,
+	}
+
+	if rawJobType := query.Get("job_type"); rawJobType != "" {
+		filter.JobType = &rawJobType
+	}
+
+	if rawQueue := query.Get("queue"); rawQueue != "" {
+		filter.Queue = &rawQueue
+	}
+
+	if rawReplayed := query.Get("replayed"); rawReplayed != "" {
+		replayed, err := strconv.ParseBool(rawReplayed)
+		if err != nil {
+			http.Error(writer, "invalid replayed filter", http.StatusBadRequest)
+			return
+		}
+		filter.Replayed = &replayed
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+
+	deadLetters, err := s.store.ListDeadLetters(request.Context(), filter)
+	if err != nil {
+		http.Error(writer, "Failed to list dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListDeadLettersResponse{
+		DeadLetters: make([]DeadLetterResponse, 0, len(deadLetters)),
+	}
+
+	for _, deadLetter := range deadLetters {
+		response.DeadLetters = append(response.DeadLetters, newDeadLetterResponse(deadLetter))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Get a dead letter
+// @Description Fetch a dead letter with the failed job and all of its attempts and errors
+// @Tags DeadLetters
+// @Produce json
+// @Param deadLetterID path string true "Dead letter ID"
+// @Success 200 {object} DeadLetterResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/dead-letters/{deadLetterID} [get]
+func (s *Server) handleGetDeadLetter(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	deadLetterID, err := uuid.Parse(request.PathValue("deadLetterID"))
+	if err != nil {
+		http.Error(writer, "Invalid dead letter id", http.StatusBadRequest)
+		return
+	}
+
+	deadLetter, err := s.store.GetDeadLetterByID(request.Context(), deadLetterID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch dead letter", http.StatusInternalServerError)
+		return
+	}
+	if deadLetter == nil {
+		http.Error(writer, "Dead letter not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(newDeadLetterResponse(*deadLetter))
+}
+
+// @Summary Replay dead letters
+// @Description Create a new PENDING job for every selected dead letter that was not replayed yet, oldest first and at most limit of them. The failed job stays FAILED; the new job links back to it through replay_of_job_id. Dead letters whose unique_key is held by an unfinished job are skipped. remaining counts the selected dead letters still to replay.
+// @Tags DeadLetters
+// @Accept json
+// @Produce json
+// @Param request body DeadLetterSelector true "Dead letters to replay"
+// @Success 200 {object} ReplayDeadLettersResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/dead-letters/replay [post]
+func (s *Server) handleReplayDeadLetters(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var selector DeadLetterSelector
+
+	if err := json.NewDecoder(request.Body).Decode(&selector); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := deadLetterFilterFromSelector(selector)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if selector.Limit < 0 || selector.Limit > store.MaxDeadLetterReplayBatch {
+		http.Error(writer, "invalid limit", http.StatusBadRequest)
+		return
+	}
+	filter.Limit = selector.Limit
+
+	replayed, remaining, err := s.store.ReplayDeadLetters(request.Context(), filter)
+	if err != nil {
+		http.Error(writer, "Failed to replay dead letters", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("dead letters replayed", "count", len(replayed), "remaining", remaining)
+
+	response := ReplayDeadLettersResponse{
+		Replayed:  make([]ReplayedDeadLetterResponse, 0, len(replayed)),
+		Remaining: remaining,
+	}
+
+	for _, r := range replayed {
+		response.Replayed = append(response.Replayed, ReplayedDeadLetterResponse{
+			DeadLetterID: r.DeadLetterID.String(),
+			JobID:        r.JobID.String(),
+		})
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Purge dead letters
+// @Description Remove the selected dead letters. The failed jobs themselves are kept.
+// @Tags DeadLetters
+// @Accept json
+// @Produce json
+// @Param request body DeadLetterSelector true "Dead letters to purge"
+// @Success 200 {object} PurgeDeadLettersResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/dead-letters/purge [post]
+func (s *Server) handlePurgeDeadLetters(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var selector DeadLetterSelector
+
+	if err := json.NewDecoder(request.Body).Decode(&selector); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := deadLetterFilterFromSelector(selector)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	purged, err := s.store.PurgeDeadLetters(request.Context(), filter)
+	if err != nil {
+		http.Error(writer, "Failed to purge dead letters", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("dead letters purged", "count", purged)
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(PurgeDeadLettersResponse{Purged: purged})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+const (
+	// maxEventsWait bounds how long a long-polling request is held open.
+	maxEventsWait = 30 * time.Second
+
+	// eventsFallbackPollInterval is how often a long-polling request checks
+	// for new events without being woken by a notification. Notifications
+	// may be missed while the event listener reconnects, and an event that
+	// an older running transaction holds back announces nothing once it
+	// becomes visible.
+	eventsFallbackPollInterval = 5 * time.Second
+
+	// maxEventsLimit bounds how many events a single request returns.
+	maxEventsLimit = 1000
+)
+
+// @Summary Job state change feed
+// @Description Return job state transitions after the given cursor, oldest first. The feed is gap-free: resuming from the last next_cursor seen never skips an event. With wait, the request is held open until an event arrives or the wait elapses.
+// @Tags Events
+// @Produce json
+// @Param after query string false "Cursor returned as next_cursor by a previous call; omit to start at the beginning"
+// @Param job_type query string false "Only events of jobs of this type"
+// @Param queue query string false "Only events of jobs in this queue"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Param wait query int false "Seconds to wait for events when none are available (max 30)"
+// @Success 200 {object} ListEventsResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/events [get]
+func (s *Server) handleListEvents(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	query := request.URL.Query()
+
+	after, err := store.ParseEventCursor(query.Get("after"))
+	if err != nil {
+		http.Error(writer, "invalid after cursor", http.StatusBadRequest)
+		return
+	}
+
+	filter := store.EventFeedFilter{
+		After: after,
+		Limit: 100,
+	}
+
+	if rawJobType := query.Get("job_type"); rawJobType != "" {
+		filter.JobType = &rawJobType
+	}
+
+	if rawQueue := query.Get("queue"); rawQueue != "" {
+		filter.Queue = &rawQueue
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 || parsed > maxEventsLimit {
+			http.Error(writer, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	var wait time.Duration
+	if rawWait := query.Get("wait"); rawWait != "" {
+		seconds, err := strconv.Atoi(rawWait)
+		if err != nil || seconds < 0 {
+			http.Error(writer, "invalid wait", http.StatusBadRequest)
+			return
+		}
+		wait = min(time.Duration(seconds)*time.Second, maxEventsWait)
+	}
+
+	deadline := time.Now().Add(wait)
+
+	var subscription *jobEventSubscription
+
+	if wait > 0 {
+		extendWriteDeadline(writer, wait)
+
+		// Subscribing before the first read guarantees that an event
+		// committed in between still wakes the request.
+		match := func(event store.JobEventNotification) bool {
+			return (filter.JobType == nil || event.JobType == *filter.JobType) &&
+				(filter.Queue == nil || event.Queue == *filter.Queue)
+		}
+
+		subscription = s.hub.subscribe(match)
+		defer func() { s.hub.unsubscribe(subscription) }()
+	}
+
+	var (
+		events []store.FeedEvent
+		next   store.EventCursor
+		timer  *time.Timer
+	)
+
+	for {
+		events, next, err = s.store.ListEventsAfter(request.Context(), filter)
+		if err != nil {
+			http.Error(writer, "Failed to list events", http.StatusInternalServerError)
+			return
+		}
+
+		remaining := time.Until(deadline)
+		if len(events) > 0 || remaining <= 0 {
+			break
+		}
+
+		pollInterval := min(eventsFallbackPollInterval, remaining)
+		if timer == nil {
+			timer = time.NewTimer(pollInterval)
+			defer timer.Stop()
+		} else {
+			timer.Reset(pollInterval)
+		}
+
+		select {
+		case <-request.Context().Done():
+			return
+
+		case _, ok := <-subscription.events:
+			if !ok {
+				// The subscription fell behind and was dropped; a new one
+				// keeps the request responsive.
+				subscription = s.hub.subscribe(subscription.match)
+			}
+
+		case <-timer.C:
+		}
+	}
+
+	response := ListEventsResponse{
+		Events:     make([]EventResponse, 0, len(events)),
+		NextCursor: next.String(),
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, newEventResponse(event))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
@@ -2,7 +2,10 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -56,14 +59,89 @@ func (s *Server) handleMetrics() http.Handler {
 	return promhttp.Handler()
 }
 
+// jobSpecFromRequest validates a job creation request. The returned error
+// is safe to show to the client.
+func jobSpecFromRequest(
+	createRequest CreateJobRequest,
+	jobID uuid.UUID,
+	now time.Time,
+) (store.JobSpec, error) {
+	if createRequest.Type == "" || createRequest.MaxAttempts < 1 || createRequest.TimeoutSeconds <= 0 {
+		return store.JobSpec{}, errors.New("invalid job parameters")
+	}
+
+	retryPolicy := store.DefaultRetryPolicy
+	if createRequest.RetryPolicy != nil {
+		retryPolicy = store.RetryPolicy{
+			InitialDelay: time.Duration(createRequest.RetryPolicy.InitialDelaySeconds) * time.Second,
+			Multiplier:   createRequest.RetryPolicy.Multiplier,
+			MaxDelay:     time.Duration(createRequest.RetryPolicy.MaxDelaySeconds) * time.Second,
+			Jitter:       createRequest.RetryPolicy.Jitter,
+		}
+
+		if err := retryPolicy.Validate(); err != nil {
+			return store.JobSpec{}, errors.New("invalid retry policy")
+		}
+	}
+
+	if createRequest.DelaySeconds < 0 || (createRequest.DelaySeconds > 0 && createRequest.RunAt != nil) {
+		return store.JobSpec{}, errors.New("invalid job schedule")
+	}
+
+	runAt := createRequest.RunAt
+	if createRequest.DelaySeconds > 0 {
+		delayedUntil := now.Add(time.Duration(createRequest.DelaySeconds) * time.Second)
+		runAt = &delayedUntil
+	}
+
+	payloadBytes, err := json.Marshal(createRequest.Payload)
+	if err != nil {
+		return store.JobSpec{}, errors.New("invalid payload")
+	}
+
+	if createRequest.ConcurrencyLimit < 0 || (createRequest.ConcurrencyLimit > 0 && createRequest.ConcurrencyKey == "") {
+		return store.JobSpec{}, errors.New("invalid concurrency limit")
+	}
+
+	switch createRequest.OnConflict {
+	case "", store.UniqueConflictReject, store.UniqueConflictReturnExisting, store.UniqueConflictReplace:
+	default:
+		return store.JobSpec{}, errors.New("invalid on_conflict policy")
+	}
+
+	return store.JobSpec{
+		ID:               jobID,
+		Type:             createRequest.Type,
+		Payload:          payloadBytes,
+		MaxAttempts:      createRequest.MaxAttempts,
+		TimeoutSeconds:   createRequest.TimeoutSeconds,
+		RetryPolicy:      retryPolicy,
+		RunAt:            runAt,
+		Priority:         createRequest.Priority,
+		Queue:            createRequest.Queue,
+		UniqueKey:        createRequest.UniqueKey,
+		OnConflict:       createRequest.OnConflict,
+		ConcurrencyKey:   createRequest.ConcurrencyKey,
+		ConcurrencyLimit: createRequest.ConcurrencyLimit,
+	}, nil
+}
+
 // @Summary Create a new job
-// @Description Create a job in the PENDING state
+// @Description Create a job in the PENDING state. Set run_at or delay_seconds to defer its first run. Jobs with a higher priority are leased first. Jobs without a queue go to the default queue.
+// @Description With an Idempotency-Key header, retrying the same request returns the job created by the first one instead of creating another.
+// @Description With a unique_key, at most one unfinished job holds the key; on_conflict chooses whether a duplicate is rejected (reject, the default), answered with the existing job (return_existing), or replaces the existing job if it is still PENDING (replace).
+// @Description With a concurrency_key, at most concurrency_limit (default 1) jobs sharing the key are scheduled or running at once.
+// @Description With wait, the response is held until the job reaches a terminal state or the wait passes, and includes the job.
 // @Tags Jobs
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Client-chosen key that makes retries of this request safe"
 // @Param request body CreateJobRequest true "Job creation payload"
+// @Param wait query string false "How long to wait for the job to finish, as a duration (30s) or seconds (max 60s)"
+// @Success 200 {object} CreateJobResponse "Existing job with the same unique_key"
 // @Success 201 {object} CreateJobResponse
 // @Failure 400 {string} string
+// @Failure 409 {string} string "Idempotency-Key reused with a different request, or unique_key taken"
 // @Failure 500 {string} string
 // @Router /v1/jobs [post]
 func (s *Server) handleCreateJob(
@@ -77,39 +155,137 @@ func (s *Server) handleCreateJob(
 		return
 	}
 
-	if createRequest.MaxAttempts < 1 || createRequest.TimeoutSeconds <= 0 {
-		http.Error(writer, "invalid job parameters", http.StatusBadRequest)
+	spec, err := jobSpecFromRequest(createRequest, uuid.New(), time.Now())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	jobID := uuid.New()
+	var wait time.Duration
+	if rawWait := request.URL.Query().Get("wait"); rawWait != "" {
+		wait, err = parseWaitTimeout(rawWait)
+		if err != nil {
+			http.Error(writer, "invalid wait", http.StatusBadRequest)
+			return
+		}
+		extendWriteDeadline(writer, wait)
+	}
 
-	payloadBytes, err := json.Marshal(createRequest.Payload)
+	idempotencyKey := request.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		jobID, err := s.store.CreateJob(request.Context(), spec)
+		if err != nil {
+			writeCreateJobError(writer, err)
+			return
+		}
+
+		creation := store.JobCreation{
+			JobID:    jobID,
+			State:    store.JobPending,
+			Existing: jobID != spec.ID,
+		}
+
+		if creation.Existing {
+			job, err := s.store.GetJobByID(request.Context(), jobID)
+			if err != nil || job == nil {
+				http.Error(writer, "failed to fetch existing job", http.StatusInternalServerError)
+				return
+			}
+			creation.State = job.State
+		}
+
+		s.writeCreatedJob(writer, request, spec, creation, wait)
+		return
+	}
+
+	// The decoded request is hashed rather than the raw body, so that
+	// formatting differences between retries do not count as a new request.
+	canonicalRequest, err := json.Marshal(createRequest)
 	if err != nil {
 		http.Error(writer, "invalid payload", http.StatusBadRequest)
 		return
 	}
+	requestHash := sha256.Sum256(canonicalRequest)
 
-	err = s.store.CreateJob(
-		request.Context(),
-		jobID,
-		payloadBytes,
-		createRequest.MaxAttempts,
-		createRequest.TimeoutSeconds,
-	)
+	creation, err := s.store.CreateJobIdempotent(request.Context(), spec, store.IdempotencyKey{
+		Key:         idempotencyKey,
+		RequestHash: hex.EncodeToString(requestHash[:]),
+		ExpiresAt:   time.Now().Add(s.config.IdempotencyKeyRetention),
+	})
 	if err != nil {
-		http.Error(writer, "failed to create job", http.StatusInternalServerError)
+		writeCreateJobError(writer, err)
 		return
 	}
 
-	LoggerFromContext(request.Context()).Info("job created", "job_id", jobID.String())
+	s.writeCreatedJob(writer, request, spec, creation, wait)
+}
+
+func writeCreateJobError(writer http.ResponseWriter, err error) {
+	switch err {
+	case store.ErrIdempotencyKeyConflict:
+		http.Error(writer, "Idempotency-Key already used for a different request", http.StatusConflict)
+	case store.ErrUniqueJobExists:
+		http.Error(writer, "An unfinished job with this unique_key already exists", http.StatusConflict)
+	default:
+		http.Error(writer, "failed to create job", http.StatusInternalServerError)
+	}
+}
+
+// writeCreatedJob writes the response of a job creation. A replayed creation
+// gets the same response as the original one, flagged with the
+// Idempotent-Replayed header. When the request collapsed into an existing
+// unique job, that job is reported with 200 instead of 201. A positive wait
+// holds the response until the job finishes or the wait passes.
+func (s *Server) writeCreatedJob(
+	writer http.ResponseWriter,
+	request *http.Request,
+	spec store.JobSpec,
+	creation store.JobCreation,
+	wait time.Duration,
+) {
+	logger := LoggerFromContext(request.Context())
+	jobID := creation.JobID
+
 	response := CreateJobResponse{
 		JobID: jobID.String(),
-		State: "PENDING",
+		State: creation.State,
+	}
+
+	status := http.StatusCreated
+	if creation.Existing {
+		status = http.StatusOK
+	}
+
+	switch {
+	case creation.Replayed:
+		writer.Header().Set("Idempotent-Replayed", "true")
+		logger.Info("job creation replayed", "job_id", jobID.String())
+
+	case creation.Existing:
+		logger.Info("existing unique job returned", "job_id", jobID.String(), "unique_key", spec.UniqueKey)
+
+	default:
+		logger.Info("job created", "job_id", jobID.String(), "job_type", spec.Type, "queue", spec.Queue)
+	}
+
+	if wait > 0 {
+		job, err := s.waitForJob(request.Context(), jobID, wait)
+		if err != nil || job == nil {
+			if request.Context().Err() != nil {
+				return
+			}
+
+			http.Error(writer, "failed to fetch job", http.StatusInternalServerError)
+			return
+		}
+
+		jobResponse := newJobResponse(*job)
+		response.State = job.State
+		response.Job = &jobResponse
 	}
 
 	writer.Header().Set("Content-Type", "application/json")
-	writer.WriteHeader(http.StatusCreated)
+	writer.WriteHeader(status)
 	_ = json.NewEncoder(writer).Encode(response)
 }
 
@@ -179,30 +355,179 @@ func (s *Server) handleGetJob(
 		return
 	}
 
-	response := JobResponse{
-		JobID:          job.ID.String(),
-		State:          job.State,
-		Payload:        job.Payload,
-		MaxAttempts:    job.MaxAttempts,
-		CurrentAttempt: job.CurrentAttempt,
-		TimeoutSeconds: job.TimeoutSeconds,
-		LastError:      job.LastError,
-		CreatedAt:      job.CreatedAt,
-		UpdatedAt:      job.UpdatedAt,
-		CancelledAt:    job.CancelledAt,
+	response := newJobResponse(*job)
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary List job events
+// @Description Return the audit log of a job, oldest first: its creation and every state transition, with the actor that caused it and the reason
+// @Tags Jobs
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} ListJobEventsResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/events [get]
+func (s *Server) handleListJobEvents(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.store.GetJobByID(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	events, err := s.store.ListJobEvents(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to list job events", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListJobEventsResponse{
+		JobID:  jobID.String(),
+		Events: make([]JobEventResponse, 0, len(events)),
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, JobEventResponse{
+			FromState: event.FromState,
+			ToState:   event.ToState,
+			Actor:     event.Actor,
+			Reason:    event.Reason,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary List job attempts
+// @Description List every execution attempt of a job, oldest first, with the worker and lease holder that ran it and how it ended
+// @Tags Jobs
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} ListJobAttemptsResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/attempts [get]
+func (s *Server) handleListJobAttempts(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.store.GetJobByID(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	attempts, err := s.store.ListJobAttempts(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to list job attempts", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListJobAttemptsResponse{
+		JobID:    jobID.String(),
+		Attempts: make([]JobAttemptResponse, 0, len(attempts)),
+	}
+
+	for _, attempt := range attempts {
+		response.Attempts = append(response.Attempts, newJobAttemptResponse(attempt))
 	}
 
 	writer.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(writer).Encode(response)
 }
 
+// @Summary Get job result
+// @Description Get the result a job reported when it completed, until the result's retention period ends
+// @Tags Jobs
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} JobResultResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/result [get]
+func (s *Server) handleGetJobResult(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := s.store.GetJobByID(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	if job.State != store.JobCompleted {
+		http.Error(writer, "Job is not completed", http.StatusConflict)
+		return
+	}
+
+	result, err := s.store.GetJobResult(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job result", http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		http.Error(writer, "Job result not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(newJobResultResponse(*result))
+}
+
 // @Summary List jobs
-// @Description List jobs with optional state filtering and limit
+// @Description List jobs with optional state, queue and priority filtering, sorting and limit
 // @Tags Jobs
 // @Produce json
 // @Param state query string false "Filter by job state"
+// @Param queue query string false "Filter by queue"
+// @Param delayed query bool false "Only PENDING jobs whose next run is in the future"
+// @Param min_priority query int false "Only jobs with at least this priority"
+// @Param max_priority query int false "Only jobs with at most this priority"
+// @Param sort query string false "Sort order: created_at (default, newest first) or priority (highest first)"
 // @Param limit query int false "Maximum number of jobs (default 100)"
 // @Success 200 {object} ListJobsResponse
+// @Failure 400 {string} string
 // @Failure 500 {string} string
 // @Router /v1/jobs [get]
 func (s *Server) handleListJobs(
@@ -211,22 +536,61 @@ func (s *Server) handleListJobs(
 ) {
 	query := request.URL.Query()
 
-	var (
-		state *string
-		limit = 100
-	)
+	filter := store.ListJobsFilter{
+		Limit: 100,
+	}
 
 	if rawState := query.Get("state"); rawState != "" {
-		state = &rawState
+		filter.State = &rawState
+	}
+
+	if rawQueue := query.Get("queue"); rawQueue != "" {
+		filter.Queue = &rawQueue
+	}
+
+	if rawDelayed := query.Get("delayed"); rawDelayed != "" {
+		delayed, err := strconv.ParseBool(rawDelayed)
+		if err != nil {
+			http.Error(writer, "invalid delayed filter", http.StatusBadRequest)
+			return
+		}
+		filter.Delayed = delayed
+	}
+
+	if rawMinPriority := query.Get("min_priority"); rawMinPriority != "" {
+		minPriority, err := strconv.Atoi(rawMinPriority)
+		if err != nil {
+			http.Error(writer, "invalid min_priority filter", http.StatusBadRequest)
+			return
+		}
+		filter.MinPriority = &minPriority
+	}
+
+	if rawMaxPriority := query.Get("max_priority"); rawMaxPriority != "" {
+		maxPriority, err := strconv.Atoi(rawMaxPriority)
+		if err != nil {
+			http.Error(writer, "invalid max_priority filter", http.StatusBadRequest)
+			return
+		}
+		filter.MaxPriority = &maxPriority
+	}
+
+	switch query.Get("sort") {
+	case "", "created_at":
+	case "priority":
+		filter.SortByPriority = true
+	default:
+		http.Error(writer, "invalid sort order", http.StatusBadRequest)
+		return
 	}
 
 	if rawLimit := query.Get("limit"); rawLimit != "" {
 		if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed > 0 {
-			limit = parsed
+			filter.Limit = parsed
 		}
 	}
 
-	jobs, err := s.store.ListJobs(request.Context(), state, limit)
+	jobs, err := s.store.ListJobs(request.Context(), filter)
 	if err != nil {
 		http.Error(writer, "Failed to list jobs", http.StatusInternalServerError)
 		return
@@ -237,18 +601,7 @@ func (s *Server) handleListJobs(
 	}
 
 	for _, job := range jobs {
-		response.Jobs = append(response.Jobs, JobResponse{
-			JobID:          job.ID.String(),
-			State:          job.State,
-			Payload:        job.Payload,
-			MaxAttempts:    job.MaxAttempts,
-			CurrentAttempt: job.CurrentAttempt,
-			TimeoutSeconds: job.TimeoutSeconds,
-			LastError:      job.LastError,
-			CreatedAt:      job.CreatedAt,
-			UpdatedAt:      job.UpdatedAt,
-			CancelledAt:    job.CancelledAt,
-		})
+		response.Jobs = append(response.Jobs, newJobResponse(job))
 	}
 
 	writer.Header().Set("Content-Type", "application/json")
@@ -288,13 +641,86 @@ func (s *Server) handleAcquireLease(
 		return
 	}
 
-	jobID, expiresAt, err := s.store.AcquireJobLease(request.Context(), schedulerID, time.Duration(req.LeaseDurationSeconds)*time.Second)
+	lease, err := s.store.AcquireJobLease(request.Context(), schedulerID, time.Duration(req.LeaseDurationSeconds)*time.Second)
 	if err != nil {
 		writer.WriteHeader(http.StatusNoContent)
 		return
 	}
 
 	response := AcquireLeaseResponse{
+		JobID:          lease.JobID.String(),
+		FencingToken:   lease.FencingToken,
+		LeaseExpiresAt: lease.ExpiresAt,
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Renew job lease
+// @Description Extend the lease of a SCHEDULED or RUNNING job held by the caller
+// @Tags Internal-Worker
+// @Accept json
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Param request body RenewLeaseRequest true "Renewal request"
+// @Success 200 {object} RenewLeaseResponse
+// @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /internal/jobs/{jobID}/lease/renew [post]
+func (s *Server) handleRenewLease(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobIDParam := request.PathValue("jobID")
+
+	jobID, err := uuid.Parse(jobIDParam)
+	if err != nil {
+		http.Error(writer, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	var renewRequest RenewLeaseRequest
+	if err := json.NewDecoder(request.Body).Decode(&renewRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	holderID, err := uuid.Parse(renewRequest.HolderID)
+	if err != nil {
+		http.Error(writer, "invalid holder_id", http.StatusBadRequest)
+		return
+	}
+
+	if renewRequest.FencingToken <= 0 {
+		http.Error(writer, "fencing token required", http.StatusBadRequest)
+		return
+	}
+
+	if renewRequest.ExtensionSeconds <= 0 {
+		http.Error(writer, "invalid lease extension", http.StatusBadRequest)
+		return
+	}
+
+	expiresAt, err := s.store.RenewLease(
+		request.Context(),
+		jobID,
+		holderID,
+		renewRequest.FencingToken,
+		time.Duration(renewRequest.ExtensionSeconds)*time.Second,
+	)
+	if err != nil {
+		if err == store.ErrLeaseNotHeld {
+			http.Error(writer, "lease not held", http.StatusConflict)
+			return
+		}
+
+		http.Error(writer, "failed to renew lease", http.StatusInternalServerError)
+		return
+	}
+
+	response := RenewLeaseResponse{
 		JobID:          jobID.String(),
 		LeaseExpiresAt: expiresAt,
 	}
@@ -327,8 +753,10 @@ func (s *Server) handleRecoverLeases(
 // @Summary Start job execution
 // @Description Transition a job from SCHEDULED to RUNNING
 // @Tags Internal-Worker
+// @Accept json
 // @Produce json
 // @Param jobID path string true "Job ID"
+// @Param request body StartJobRequest true "Lease fencing token"
 // @Success 200 {object} StartJobResponse
 // @Failure 400 {string} string
 // @Failure 409 {string} string
@@ -346,8 +774,29 @@ func (s *Server) handleStartJob(
 		return
 	}
 
-	err = s.store.MarkJobRunning(request.Context(), jobID)
+	var startRequest StartJobRequest
+	if err := json.NewDecoder(request.Body).Decode(&startRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if startRequest.FencingToken <= 0 {
+		http.Error(writer, "fencing token required", http.StatusBadRequest)
+		return
+	}
+
+	err = s.store.MarkJobRunning(request.Context(), jobID, startRequest.FencingToken)
 	if err != nil {
+		if err == store.ErrStaleFencingToken {
+			http.Error(writer, "stale fencing token", http.StatusConflict)
+			return
+		}
+
+		if err == store.ErrLeaseNotHeld {
+			http.Error(writer, "lease not held", http.StatusConflict)
+			return
+		}
+
 		if err == store.ErrInvalidStateTransition {
 			http.Error(writer, "job cannot be started", http.StatusConflict)
 			return
@@ -366,14 +815,97 @@ func (s *Server) handleStartJob(
 	_ = json.NewEncoder(writer).Encode(response)
 }
 
+// @Summary Report job progress
+// @Description Replace the progress of a RUNNING job and extend its lease. Only the current lease holder may report.
+// @Tags Internal-Worker
+// @Accept json
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Param request body ReportJobProgressRequest true "Lease fencing token and progress"
+// @Success 200 {object} ReportJobProgressResponse
+// @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /internal/jobs/{jobID}/progress [post]
+func (s *Server) handleReportJobProgress(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobIDParam := request.PathValue("jobID")
+
+	jobID, err := uuid.Parse(jobIDParam)
+	if err != nil {
+		http.Error(writer, "invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	var progressRequest ReportJobProgressRequest
+	if err := json.NewDecoder(request.Body).Decode(&progressRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if progressRequest.FencingToken <= 0 {
+		http.Error(writer, "fencing token required", http.StatusBadRequest)
+		return
+	}
+
+	expiresAt, err := s.store.ReportJobProgress(
+		request.Context(),
+		jobID,
+		progressRequest.FencingToken,
+		store.JobProgress{
+			Percent: progressRequest.Percent,
+			Stage:   progressRequest.Stage,
+			Message: progressRequest.Message,
+		},
+		store.DefaultLeaseDuration,
+	)
+	if err != nil {
+		if err == store.ErrInvalidJobProgress {
+			http.Error(writer, "percent must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+
+		if err == store.ErrStaleFencingToken {
+			http.Error(writer, "stale fencing token", http.StatusConflict)
+			return
+		}
+
+		if err == store.ErrLeaseNotHeld {
+			http.Error(writer, "lease not held", http.StatusConflict)
+			return
+		}
+
+		if err == store.ErrInvalidStateTransition {
+			http.Error(writer, "job is not running", http.StatusConflict)
+			return
+		}
+
+		http.Error(writer, "failed to report job progress", http.StatusInternalServerError)
+		return
+	}
+
+	response := ReportJobProgressResponse{
+		JobID:          jobID.String(),
+		LeaseExpiresAt: expiresAt,
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
 // @Summary Complete job
 // @Description Mark a RUNNING job as COMPLETED
 // @Tags Internal-Worker
+// @Accept json
 // @Produce json
 // @Param jobID path string true "Job ID"
+// @Param request body CompleteJobRequest true "Lease fencing token and optional result"
 // @Success 200 {object} CompleteJobResponse
 // @Failure 400 {string} string
 // @Failure 409 {string} string
+// @Failure 413 {string} string
 // @Failure 500 {string} string
 // @Router /internal/jobs/{jobID}/complete [post]
 func (s *Server) handleCompleteJob(
@@ -388,8 +920,49 @@ func (s *Server) handleCompleteJob(
 		return
 	}
 
-	err = s.store.CompleteJob(request.Context(), jobID)
+	var completeRequest CompleteJobRequest
+	if err := json.NewDecoder(request.Body).Decode(&completeRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if completeRequest.FencingToken <= 0 {
+		http.Error(writer, "fencing token required", http.StatusBadRequest)
+		return
+	}
+
+	var result *store.JobResult
+
+	if completeRequest.Result != nil {
+		resultBytes, err := json.Marshal(completeRequest.Result)
+		if err != nil {
+			http.Error(writer, "invalid result", http.StatusBadRequest)
+			return
+		}
+
+		if len(resultBytes) > s.config.JobResultMaxBytes {
+			http.Error(writer, "result too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		result = &store.JobResult{
+			Result:    resultBytes,
+			ExpiresAt: time.Now().Add(s.config.JobResultRetention),
+		}
+	}
+
+	err = s.store.CompleteJob(request.Context(), jobID, completeRequest.FencingToken, result)
 	if err != nil {
+		if err == store.ErrStaleFencingToken {
+			http.Error(writer, "stale fencing token", http.StatusConflict)
+			return
+		}
+
+		if err == store.ErrLeaseNotHeld {
+			http.Error(writer, "lease not held", http.StatusConflict)
+			return
+		}
+
 		if err == store.ErrInvalidStateTransition {
 			http.Error(writer, "job cannot be completed", http.StatusConflict)
 			return
@@ -409,7 +982,7 @@ func (s *Server) handleCompleteJob(
 }
 
 // @Summary Fail job
-// @Description Mark a RUNNING job as FAILED with retry semantics
+// @Description Record a failed attempt of a RUNNING job. The response state is PENDING when the job will be retried and FAILED otherwise.
 // @Tags Internal-Worker
 // @Accept json
 // @Produce json
@@ -438,18 +1011,34 @@ func (s *Server) handleFailJob(
 		return
 	}
 
+	if failRequest.FencingToken <= 0 {
+		http.Error(writer, "fencing token required", http.StatusBadRequest)
+		return
+	}
+
 	if failRequest.Error == "" {
 		http.Error(writer, "error message required", http.StatusBadRequest)
 		return
 	}
 
-	err = s.store.FailJob(
+	state, err := s.store.FailJob(
 		request.Context(),
 		jobID,
+		failRequest.FencingToken,
 		failRequest.Error,
 		failRequest.Retryable,
 	)
 	if err != nil {
+		if err == store.ErrStaleFencingToken {
+			http.Error(writer, "stale fencing token", http.StatusConflict)
+			return
+		}
+
+		if err == store.ErrLeaseNotHeld {
+			http.Error(writer, "lease not held", http.StatusConflict)
+			return
+		}
+
 		if err == store.ErrInvalidStateTransition {
 			http.Error(writer, "job cannot be failed", http.StatusConflict)
 			return
@@ -461,7 +1050,7 @@ func (s *Server) handleFailJob(
 
 	response := FailJobResponse{
 		JobID: jobID.String(),
-		State: store.JobFailed,
+		State: state,
 	}
 
 	writer.Header().Set("Content-Type", "application/json")
@@ -495,3 +1084,48 @@ func (s *Server) handleWorkerHeartbeat(
 
 	writer.WriteHeader(http.StatusNoContent)
 }
+
+// @Summary Recover worker leases
+// @Description Release every lease claimed by a worker so its jobs can be rescheduled
+// @Tags Internal-Scheduler
+// @Produce json
+// @Param workerID path string true "Worker ID"
+// @Success 200 {object} RecoverLeasesResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /internal/workers/{workerID}/recover [post]
+func (s *Server) handleRecoverWorkerLeases(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	workerIDParam := request.PathValue("workerID")
+
+	workerID, err := uuid.Parse(workerIDParam)
+	if err != nil {
+		http.Error(writer, "invalid worker id", http.StatusBadRequest)
+		return
+	}
+
+	recovered, err := s.store.RecoverWorkerLeases(request.Context(), workerID)
+	if err != nil {
+		http.Error(writer, "failed to recover worker leases", http.StatusInternalServerError)
+		return
+	}
+
+	response := RecoverLeasesResponse{
+		RecoveredJobIDs: make([]string, 0, len(recovered)),
+	}
+
+	for _, jobID := range recovered {
+		response.RecoveredJobIDs = append(response.RecoveredJobIDs, jobID.String())
+	}
+
+	LoggerFromContext(request.Context()).Info(
+		"worker leases recovered",
+		"worker_id", workerID.String(),
+		"recovered", len(recovered),
+	)
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCompleteJobRejectsResultOverConfiguredLimit(t *testing.T) {
+	// The result is rejected before the store is reached, so none is needed.
+	server := NewServer(nil, slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		JobResultMaxBytes: 16,
+	})
+
+	body := `{"fencing_token": 1, "result": {"message": "well within the default limit"}}`
+	request := httptest.NewRequest(
+		http.MethodPost,
+		"/internal/jobs/complete",
+		strings.NewReader(body),
+	)
+	request.SetPathValue("jobID", uuid.NewString())
+	recorder := httptest.NewRecorder()
+
+	server.handleCompleteJob(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusRequestEntityTooLarge, recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+const (
+	// logsPollInterval is how often a log stream checks a RUNNING job for
+	// new chunks.
+	logsPollInterval = 500 * time.Millisecond
+
+	// maxJobLogsLimit bounds how many chunks a single request returns.
+	maxJobLogsLimit = 1000
+)
+
+// @Summary Job logs
+// @Description Return the log output written by a job's handler, oldest first, optionally for a single attempt. With follow=true the logs are streamed as Server-Sent "log" events, including output written while the job runs, and the stream ends once the job reaches a terminal state.
+// @Tags Jobs
+// @Produce json
+// @Produce text/event-stream
+// @Param jobID path string true "Job ID"
+// @Param attempt query int false "Only logs of this attempt, numbered as in /v1/jobs/{jobID}/attempts"
+// @Param after query int false "Only chunks with a greater id, e.g. next_after of a previous call"
+// @Param limit query int false "Maximum number of chunks (default 100, max 1000)"
+// @Param follow query bool false "Stream logs until the job finishes"
+// @Success 200 {object} ListJobLogsResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/logs [get]
+func (s *Server) handleListJobLogs(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	query := request.URL.Query()
+
+	filter := store.JobLogFilter{
+		JobID: jobID,
+		Limit: 100,
+	}
+
+	if rawAttempt := query.Get("attempt"); rawAttempt != "" {
+		attempt, err := strconv.Atoi(rawAttempt)
+		if err != nil || attempt < 1 {
+			http.Error(writer, "invalid attempt", http.StatusBadRequest)
+			return
+		}
+		filter.Attempt = attempt
+	}
+
+	if rawAfter := query.Get("after"); rawAfter != "" {
+		after, err := strconv.ParseInt(rawAfter, 10, 64)
+		if err != nil || after < 0 {
+			http.Error(writer, "invalid after", http.StatusBadRequest)
+			return
+		}
+		filter.AfterID = after
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 || parsed > maxJobLogsLimit {
+			http.Error(writer, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	follow := false
+	if rawFollow := query.Get("follow"); rawFollow != "" {
+		follow, err = strconv.ParseBool(rawFollow)
+		if err != nil {
+			http.Error(writer, "invalid follow", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if follow {
+		s.followJobLogs(writer, request, filter)
+		return
+	}
+
+	job, err := s.store.GetJobByID(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	chunks, err := s.store.ListJobLogs(request.Context(), filter)
+	if err != nil {
+		http.Error(writer, "Failed to list job logs", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListJobLogsResponse{
+		JobID:     jobID.String(),
+		Logs:      make([]JobLogResponse, 0, len(chunks)),
+		NextAfter: filter.AfterID,
+	}
+
+	for _, chunk := range chunks {
+		response.Logs = append(response.Logs, newJobLogResponse(chunk))
+		response.NextAfter = chunk.ID
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// followJobLogs streams the chunks selected by filter as they are written.
+// Chunks are polled for only while the job is RUNNING; otherwise the stream
+// waits for the job's next state change. Workers write a job's remaining
+// output before recording its outcome, so the read that follows the
+// terminal state change drains its log.
+func (s *Server) followJobLogs(
+	writer http.ResponseWriter,
+	request *http.Request,
+	filter store.JobLogFilter,
+) {
+	match := func(event store.JobEventNotification) bool {
+		return event.JobID == filter.JobID
+	}
+
+	// Subscribing before reading the job guarantees that a state change
+	// cannot fall between the two.
+	subscription := s.hub.subscribe(match)
+	defer func() { s.hub.unsubscribe(subscription) }()
+
+	job, err := s.store.GetJobByID(request.Context(), filter.JobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	state := job.State
+
+	controller := startEventStream(writer)
+
+	keepAlive := time.NewTicker(watchKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	poll := time.NewTimer(logsPollInterval)
+	defer poll.Stop()
+
+	for {
+		for {
+			chunks, err := s.store.ListJobLogs(request.Context(), filter)
+			if err != nil {
+				return
+			}
+
+			for _, chunk := range chunks {
+				if err := writeServerSentEvent(writer, controller, "log", newJobLogResponse(chunk)); err != nil {
+					return
+				}
+				filter.AfterID = chunk.ID
+			}
+
+			if len(chunks) < filter.Limit {
+				break
+			}
+		}
+
+		if store.IsTerminalState(state) {
+			return
+		}
+
+		var pollC <-chan time.Time
+		if state == store.JobRunning {
+			poll.Reset(logsPollInterval)
+			pollC = poll.C
+		}
+
+		select {
+		case <-request.Context().Done():
+			return
+
+		case event, ok := <-subscription.events:
+			if ok {
+				state = event.ToState
+				continue
+			}
+
+			// The subscription was dropped, possibly with a state change,
+			// so the job is read again under a new one.
+			subscription = s.hub.subscribe(match)
+
+			job, err := s.store.GetJobByID(request.Context(), filter.JobID)
+			if err != nil || job == nil {
+				return
+			}
+			state = job.State
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+
+		case <-pollC:
+		}
+	}
+}
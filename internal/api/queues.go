@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// @Summary List queues
+// @Description List configured queues and queues with unfinished jobs, with their concurrency limit and load
+// @Tags Queues
+// @Produce json
+// @Success 200 {object} ListQueuesResponse
+// @Failure 500 {string} string
+// @Router /v1/queues [get]
+func (s *Server) handleListQueues(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	queues, err := s.store.ListQueues(request.Context())
+	if err != nil {
+		http.Error(writer, "Failed to list queues", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListQueuesResponse{
+		Queues: make([]QueueResponse, 0, len(queues)),
+	}
+
+	for _, queue := range queues {
+		response.Queues = append(response.Queues, newQueueResponse(queue))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Configure a queue
+// @Description Set the maximum number of scheduled and running jobs of a queue. A null max_concurrency removes the limit.
+// @Tags Queues
+// @Accept json
+// @Param queue path string true "Queue name"
+// @Param request body SetQueueRequest true "Queue settings"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/queues/{queue} [put]
+func (s *Server) handleSetQueue(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	queue := request.PathValue("queue")
+	if queue == "" {
+		http.Error(writer, "Invalid queue", http.StatusBadRequest)
+		return
+	}
+
+	var setRequest SetQueueRequest
+
+	if err := json.NewDecoder(request.Body).Decode(&setRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if setRequest.MaxConcurrency != nil && *setRequest.MaxConcurrency < 1 {
+		http.Error(writer, "invalid max_concurrency", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.SetQueueConcurrency(request.Context(), queue, setRequest.MaxConcurrency); err != nil {
+		http.Error(writer, "Failed to configure queue", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("queue configured", "queue", queue)
+
+	writer.WriteHeader(http.StatusNoContent)
+}
@@ -1,18 +1,114 @@
 package api
 
+import "time"
+
 type CreateJobRequest struct {
-	Type           string         `json:"type"`
-	Payload        map[string]any `json:"payload"`
-	MaxAttempts    int            `json:"max_attempts"`
-	TimeoutSeconds int            `json:"timeout_seconds"`
+	Type             string              `json:"type"`
+	Payload          map[string]any      `json:"payload"`
+	MaxAttempts      int                 `json:"max_attempts"`
+	TimeoutSeconds   int                 `json:"timeout_seconds"`
+	RetryPolicy      *RetryPolicyRequest `json:"retry_policy,omitempty"`
+	RunAt            *time.Time          `json:"run_at,omitempty"`
+	DelaySeconds     int                 `json:"delay_seconds,omitempty"`
+	Priority         int                 `json:"priority,omitempty"`
+	Queue            string              `json:"queue,omitempty"`
+	UniqueKey        string              `json:"unique_key,omitempty"`
+	OnConflict       string              `json:"on_conflict,omitempty"`
+	ConcurrencyKey   string              `json:"concurrency_key,omitempty"`
+	ConcurrencyLimit int                 `json:"concurrency_limit,omitempty"`
+}
+
+type RetryPolicyRequest struct {
+	InitialDelaySeconds int     `json:"initial_delay_seconds"`
+	Multiplier          float64 `json:"multiplier"`
+	MaxDelaySeconds     int     `json:"max_delay_seconds"`
+	Jitter              float64 `json:"jitter"`
+}
+
+type StartJobRequest struct {
+	FencingToken int64 `json:"fencing_token"`
+}
+
+// CompleteJobRequest may carry any JSON value as the job's result.
+type CompleteJobRequest struct {
+	FencingToken int64 `json:"fencing_token"`
+	Result       any   `json:"result,omitempty"`
+}
+
+// ReportJobProgressRequest replaces the job's progress; an omitted stage or
+// message clears it.
+type ReportJobProgressRequest struct {
+	FencingToken int64   `json:"fencing_token"`
+	Percent      int     `json:"percent"`
+	Stage        *string `json:"stage,omitempty"`
+	Message      *string `json:"message,omitempty"`
 }
 
 type FailJobRequest struct {
-	Error     string `json:"error"`
-	Retryable bool   `json:"retryable"`
+	FencingToken int64  `json:"fencing_token"`
+	Error        string `json:"error"`
+	Retryable    bool   `json:"retryable"`
 }
 
 type AcquireLeaseRequest struct {
 	SchedulerID          string `json:"scheduler_id"`
 	LeaseDurationSeconds int    `json:"lease_duration_seconds"`
 }
+
+type RenewLeaseRequest struct {
+	HolderID         string `json:"holder_id"`
+	FencingToken     int64  `json:"fencing_token"`
+	ExtensionSeconds int    `json:"extension_seconds"`
+}
+
+type ScheduleRequest struct {
+	Name           string         `json:"name"`
+	CronExpression string         `json:"cron_expression"`
+	Timezone       string         `json:"timezone,omitempty"`
+	CatchUpPolicy  string         `json:"catch_up_policy,omitempty"`
+	JobType        string         `json:"job_type"`
+	Payload        map[string]any `json:"payload"`
+	MaxAttempts    int            `json:"max_attempts"`
+	TimeoutSeconds int            `json:"timeout_seconds"`
+	Paused         bool           `json:"paused,omitempty"`
+}
+
+type SetQueueRequest struct {
+	MaxConcurrency *int `json:"max_concurrency"`
+}
+
+type CreateWorkflowRequest struct {
+	Name          string               `json:"name"`
+	FailurePolicy string               `json:"failure_policy,omitempty"`
+	Jobs          []WorkflowJobRequest `json:"jobs"`
+}
+
+// WorkflowJobRequest is a job of a workflow. Key names the job within the
+// request so that other jobs can list it in depends_on.
+type WorkflowJobRequest struct {
+	CreateJobRequest
+	Key       string   `json:"key"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// DeadLetterSelector picks the dead letters to replay or purge. IDs, JobType
+// and Queue narrow the selection; All must be set to select every dead
+// letter without narrowing it. Limit caps how many dead letters one replay
+// handles (default and maximum 1000); purges ignore it.
+type DeadLetterSelector struct {
+	IDs     []string `json:"ids,omitempty"`
+	JobType string   `json:"job_type,omitempty"`
+	Queue   string   `json:"queue,omitempty"`
+	All     bool     `json:"all,omitempty"`
+	Limit   int      `json:"limit,omitempty"`
+}
+
+// CreateWebhookRequest subscribes a URL to job events. Omitted filters match
+// every job; an omitted secret is generated.
+type CreateWebhookRequest struct {
+	URL     string   `json:"url"`
+	Secret  string   `json:"secret,omitempty"`
+	JobType string   `json:"job_type,omitempty"`
+	Queue   string   `json:"queue,omitempty"`
+	States  []string `json:"states,omitempty"`
+}
@@ -1,32 +1,277 @@
 package api
 
 import (
+	"encoding/json"
 	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/store"
 )
 
+// CreateJobResponse reports a created job. Job is only set when the request
+// waited for the job to finish.
 type CreateJobResponse struct {
-	JobID string `json:"job_id"`
-	State string `json:"state"`
+	JobID string       `json:"job_id"`
+	State string       `json:"state"`
+	Job   *JobResponse `json:"job,omitempty"`
 }
 
 type JobResponse struct {
-	JobID          string     `json:"job_id"`
-	State          string     `json:"state"`
-	Payload        []byte     `json:"payload"`
-	MaxAttempts    int        `json:"max_attempts"`
-	CurrentAttempt int        `json:"current_attempt"`
-	TimeoutSeconds int        `json:"timeout_seconds"`
-	LastError      *string    `json:"last_error,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	CancelledAt    *time.Time `json:"cancelled_at,omitempty"`
+	JobID            string               `json:"job_id"`
+	Type             string               `json:"type"`
+	State            string               `json:"state"`
+	Payload          []byte               `json:"payload"`
+	MaxAttempts      int                  `json:"max_attempts"`
+	CurrentAttempt   int                  `json:"current_attempt"`
+	TimeoutSeconds   int                  `json:"timeout_seconds"`
+	LastError        *string              `json:"last_error,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+	StartedAt        *time.Time           `json:"started_at,omitempty"`
+	CancelledAt      *time.Time           `json:"cancelled_at,omitempty"`
+	WorkerID         *string              `json:"worker_id,omitempty"`
+	RetryPolicy      RetryPolicyResponse  `json:"retry_policy"`
+	RunAt            *time.Time           `json:"run_at,omitempty"`
+	NextRunAt        time.Time            `json:"next_run_at"`
+	ScheduleID       *string              `json:"schedule_id,omitempty"`
+	Priority         int                  `json:"priority"`
+	Queue            string               `json:"queue"`
+	WorkflowID       *string              `json:"workflow_id,omitempty"`
+	UniqueKey        *string              `json:"unique_key,omitempty"`
+	ConcurrencyKey   *string              `json:"concurrency_key,omitempty"`
+	ConcurrencyLimit int                  `json:"concurrency_limit"`
+	ReplayOfJobID    *string              `json:"replay_of_job_id,omitempty"`
+	Progress         *JobProgressResponse `json:"progress,omitempty"`
+}
+
+type JobProgressResponse struct {
+	Percent   int       `json:"percent"`
+	Stage     *string   `json:"stage,omitempty"`
+	Message   *string   `json:"message,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RetryPolicyResponse struct {
+	InitialDelaySeconds int     `json:"initial_delay_seconds"`
+	Multiplier          float64 `json:"multiplier"`
+	MaxDelaySeconds     int     `json:"max_delay_seconds"`
+	Jitter              float64 `json:"jitter"`
+}
+
+func newJobResponse(job store.Job) JobResponse {
+	response := JobResponse{
+		JobID:          job.ID.String(),
+		Type:           job.Type,
+		State:          job.State,
+		Payload:        job.Payload,
+		MaxAttempts:    job.MaxAttempts,
+		CurrentAttempt: job.CurrentAttempt,
+		TimeoutSeconds: job.TimeoutSeconds,
+		LastError:      job.LastError,
+		CreatedAt:      job.CreatedAt,
+		UpdatedAt:      job.UpdatedAt,
+		StartedAt:      job.StartedAt,
+		CancelledAt:    job.CancelledAt,
+		RetryPolicy: RetryPolicyResponse{
+			InitialDelaySeconds: int(job.RetryPolicy.InitialDelay / time.Second),
+			Multiplier:          job.RetryPolicy.Multiplier,
+			MaxDelaySeconds:     int(job.RetryPolicy.MaxDelay / time.Second),
+			Jitter:              job.RetryPolicy.Jitter,
+		},
+		RunAt:            job.RunAt,
+		NextRunAt:        job.NextRunAt,
+		Priority:         job.Priority,
+		Queue:            job.Queue,
+		UniqueKey:        job.UniqueKey,
+		ConcurrencyKey:   job.ConcurrencyKey,
+		ConcurrencyLimit: job.ConcurrencyLimit,
+	}
+
+	if job.WorkerID != nil {
+		workerID := job.WorkerID.String()
+		response.WorkerID = &workerID
+	}
+
+	if job.ScheduleID != nil {
+		scheduleID := job.ScheduleID.String()
+		response.ScheduleID = &scheduleID
+	}
+
+	if job.WorkflowID != nil {
+		workflowID := job.WorkflowID.String()
+		response.WorkflowID = &workflowID
+	}
+
+	if job.ReplayOfJobID != nil {
+		replayOfJobID := job.ReplayOfJobID.String()
+		response.ReplayOfJobID = &replayOfJobID
+	}
+
+	if job.Progress != nil {
+		response.Progress = &JobProgressResponse{
+			Percent:   job.Progress.Percent,
+			Stage:     job.Progress.Stage,
+			Message:   job.Progress.Message,
+			UpdatedAt: job.Progress.UpdatedAt,
+		}
+	}
+
+	return response
 }
 
 type ListJobsResponse struct {
 	Jobs []JobResponse `json:"jobs"`
 }
 
+type JobAttemptResponse struct {
+	Attempt      int        `json:"attempt"`
+	WorkerID     *string    `json:"worker_id,omitempty"`
+	SchedulerID  string     `json:"scheduler_id"`
+	FencingToken int64      `json:"fencing_token"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Outcome      *string    `json:"outcome,omitempty"`
+	Error        *string    `json:"error,omitempty"`
+	Retryable    *bool      `json:"retryable,omitempty"`
+}
+
+func newJobAttemptResponse(attempt store.JobAttempt) JobAttemptResponse {
+	response := JobAttemptResponse{
+		Attempt:      attempt.Attempt,
+		SchedulerID:  attempt.SchedulerID.String(),
+		FencingToken: attempt.FencingToken,
+		StartedAt:    attempt.StartedAt,
+		FinishedAt:   attempt.FinishedAt,
+		Outcome:      attempt.Outcome,
+		Error:        attempt.Error,
+		Retryable:    attempt.Retryable,
+	}
+
+	if attempt.WorkerID != nil {
+		workerID := attempt.WorkerID.String()
+		response.WorkerID = &workerID
+	}
+
+	return response
+}
+
+type JobResultResponse struct {
+	JobID     string          `json:"job_id"`
+	Result    json.RawMessage `json:"result" swaggertype:"object"`
+	SizeBytes int             `json:"size_bytes"`
+	CreatedAt time.Time       `json:"created_at"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+func newJobResultResponse(result store.JobResult) JobResultResponse {
+	return JobResultResponse{
+		JobID:     result.JobID.String(),
+		Result:    result.Result,
+		SizeBytes: result.SizeBytes,
+		CreatedAt: result.CreatedAt,
+		ExpiresAt: result.ExpiresAt,
+	}
+}
+
+type JobLogResponse struct {
+	ID        int64     `json:"id"`
+	Attempt   int       `json:"attempt"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newJobLogResponse(chunk store.JobLogChunk) JobLogResponse {
+	return JobLogResponse{
+		ID:        chunk.ID,
+		Attempt:   chunk.Attempt,
+		Content:   chunk.Content,
+		CreatedAt: chunk.CreatedAt,
+	}
+}
+
+// ListJobLogsResponse carries log chunks in the order they were written.
+// Passing NextAfter as after resumes after the last chunk.
+type ListJobLogsResponse struct {
+	JobID     string           `json:"job_id"`
+	Logs      []JobLogResponse `json:"logs"`
+	NextAfter int64            `json:"next_after"`
+}
+
+type JobEventResponse struct {
+	FromState *string   `json:"from_state,omitempty"`
+	ToState   string    `json:"to_state"`
+	Actor     string    `json:"actor"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListJobEventsResponse struct {
+	JobID  string             `json:"job_id"`
+	Events []JobEventResponse `json:"events"`
+}
+
+type EventResponse struct {
+	Cursor    string    `json:"cursor"`
+	JobID     string    `json:"job_id"`
+	JobType   string    `json:"job_type"`
+	Queue     string    `json:"queue"`
+	FromState *string   `json:"from_state,omitempty"`
+	ToState   string    `json:"to_state"`
+	Actor     string    `json:"actor"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newEventResponse(event store.FeedEvent) EventResponse {
+	return EventResponse{
+		Cursor:    event.Cursor.String(),
+		JobID:     event.JobID.String(),
+		JobType:   event.JobType,
+		Queue:     event.Queue,
+		FromState: event.FromState,
+		ToState:   event.ToState,
+		Actor:     event.Actor,
+		Reason:    event.Reason,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// ListEventsResponse is a page of the change feed. NextCursor is passed as
+// after to fetch the following page; it is unchanged when no events were
+// returned.
+type ListEventsResponse struct {
+	Events     []EventResponse `json:"events"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// JobTransitionResponse is the data of a "transition" Server-Sent Event.
+type JobTransitionResponse struct {
+	EventID   int64     `json:"event_id"`
+	JobID     string    `json:"job_id"`
+	JobType   string    `json:"job_type"`
+	Queue     string    `json:"queue"`
+	FromState *string   `json:"from_state,omitempty"`
+	ToState   string    `json:"to_state"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListJobAttemptsResponse struct {
+	JobID    string               `json:"job_id"`
+	Attempts []JobAttemptResponse `json:"attempts"`
+}
+
 type AcquireLeaseResponse struct {
+	JobID          string    `json:"job_id"`
+	FencingToken   int64     `json:"fencing_token"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+}
+
+type RenewLeaseResponse struct {
+	JobID          string    `json:"job_id"`
+	LeaseExpiresAt time.Time `json:"lease_expires_at"`
+}
+
+type ReportJobProgressResponse struct {
 	JobID          string    `json:"job_id"`
 	LeaseExpiresAt time.Time `json:"lease_expires_at"`
 }
@@ -53,3 +298,209 @@ type FailJobResponse struct {
 type RecoverJobsResponse struct {
 	RecoveredJobIDs []string `json:"recovered_job_ids"`
 }
+
+type ScheduleResponse struct {
+	ScheduleID     string     `json:"schedule_id"`
+	Name           string     `json:"name"`
+	CronExpression string     `json:"cron_expression"`
+	Timezone       string     `json:"timezone"`
+	CatchUpPolicy  string     `json:"catch_up_policy"`
+	JobType        string     `json:"job_type"`
+	Payload        []byte     `json:"payload"`
+	MaxAttempts    int        `json:"max_attempts"`
+	TimeoutSeconds int        `json:"timeout_seconds"`
+	Paused         bool       `json:"paused"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func newScheduleResponse(schedule store.Schedule) ScheduleResponse {
+	return ScheduleResponse{
+		ScheduleID:     schedule.ID.String(),
+		Name:           schedule.Name,
+		CronExpression: schedule.CronExpression,
+		Timezone:       schedule.Timezone,
+		CatchUpPolicy:  schedule.CatchUpPolicy,
+		JobType:        schedule.JobType,
+		Payload:        schedule.Payload,
+		MaxAttempts:    schedule.MaxAttempts,
+		TimeoutSeconds: schedule.TimeoutSeconds,
+		Paused:         schedule.Paused,
+		NextRunAt:      schedule.NextRunAt,
+		LastRunAt:      schedule.LastRunAt,
+		CreatedAt:      schedule.CreatedAt,
+		UpdatedAt:      schedule.UpdatedAt,
+	}
+}
+
+type ListSchedulesResponse struct {
+	Schedules []ScheduleResponse `json:"schedules"`
+}
+
+type QueueResponse struct {
+	Name           string     `json:"name"`
+	MaxConcurrency *int       `json:"max_concurrency,omitempty"`
+	Pending        int        `json:"pending"`
+	Active         int        `json:"active"`
+	UpdatedAt      *time.Time `json:"updated_at,omitempty"`
+}
+
+func newQueueResponse(queue store.Queue) QueueResponse {
+	return QueueResponse{
+		Name:           queue.Name,
+		MaxConcurrency: queue.MaxConcurrency,
+		Pending:        queue.Pending,
+		Active:         queue.Active,
+		UpdatedAt:      queue.UpdatedAt,
+	}
+}
+
+type ListQueuesResponse struct {
+	Queues []QueueResponse `json:"queues"`
+}
+
+type CreateWorkflowResponse struct {
+	WorkflowID string                      `json:"workflow_id"`
+	Jobs       []CreateWorkflowJobResponse `json:"jobs"`
+}
+
+type CreateWorkflowJobResponse struct {
+	Key   string `json:"key"`
+	JobID string `json:"job_id"`
+	State string `json:"state"`
+}
+
+type WorkflowResponse struct {
+	WorkflowID    string                `json:"workflow_id"`
+	Name          string                `json:"name"`
+	FailurePolicy string                `json:"failure_policy"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Jobs          []WorkflowJobResponse `json:"jobs"`
+}
+
+type WorkflowJobResponse struct {
+	JobResponse
+	DependsOn []string `json:"depends_on"`
+}
+
+type DeadLetterResponse struct {
+	DeadLetterID  string               `json:"dead_letter_id"`
+	Job           JobResponse          `json:"job"`
+	Attempts      []JobAttemptResponse `json:"attempts"`
+	CreatedAt     time.Time            `json:"created_at"`
+	ReplayedJobID *string              `json:"replayed_job_id,omitempty"`
+	ReplayedAt    *time.Time           `json:"replayed_at,omitempty"`
+}
+
+func newDeadLetterResponse(deadLetter store.DeadLetter) DeadLetterResponse {
+	response := DeadLetterResponse{
+		DeadLetterID: deadLetter.ID.String(),
+		Job:          newJobResponse(deadLetter.Job),
+		Attempts:     make([]JobAttemptResponse, 0, len(deadLetter.Attempts)),
+		CreatedAt:    deadLetter.CreatedAt,
+		ReplayedAt:   deadLetter.ReplayedAt,
+	}
+
+	for _, attempt := range deadLetter.Attempts {
+		response.Attempts = append(response.Attempts, newJobAttemptResponse(attempt))
+	}
+
+	if deadLetter.ReplayedJobID != nil {
+		replayedJobID := deadLetter.ReplayedJobID.String()
+		response.ReplayedJobID = &replayedJobID
+	}
+
+	return response
+}
+
+type ListDeadLettersResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+}
+
+type ReplayedDeadLetterResponse struct {
+	DeadLetterID string `json:"dead_letter_id"`
+	JobID        string `json:"job_id"`
+}
+
+// ReplayDeadLettersResponse lists the dead letters replayed by a request.
+// Remaining counts the selected dead letters still waiting to be replayed;
+// repeat the request until it is zero.
+type ReplayDeadLettersResponse struct {
+	Replayed  []ReplayedDeadLetterResponse `json:"replayed"`
+	Remaining int64                        `json:"remaining"`
+}
+
+type PurgeDeadLettersResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+type WebhookResponse struct {
+	WebhookID string    `json:"webhook_id"`
+	URL       string    `json:"url"`
+	JobType   *string   `json:"job_type,omitempty"`
+	Queue     *string   `json:"queue,omitempty"`
+	States    []string  `json:"states,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(webhook store.Webhook) WebhookResponse {
+	return WebhookResponse{
+		WebhookID: webhook.ID.String(),
+		URL:       webhook.URL,
+		JobType:   webhook.JobType,
+		Queue:     webhook.Queue,
+		States:    webhook.States,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+// CreateWebhookResponse is the only response that reveals the webhook's
+// signing secret.
+type CreateWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryID     string     `json:"delivery_id"`
+	WebhookID      string     `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	JobID          string     `json:"job_id"`
+	Payload        []byte     `json:"payload"`
+	State          string     `json:"state"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode *int       `json:"last_status_code,omitempty"`
+	LastError      *string    `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func newWebhookDeliveryResponse(delivery store.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		DeliveryID:     delivery.ID.String(),
+		WebhookID:      delivery.WebhookID.String(),
+		EventID:        delivery.EventID,
+		JobID:          delivery.JobID.String(),
+		Payload:        delivery.Payload,
+		State:          delivery.State,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
@@ -18,17 +18,55 @@ func (s *Server) registerRoutes() {
 
 	r.HandleFunc("/v1/jobs", s.handleCreateJob).Methods(http.MethodPost)
 	r.HandleFunc("/v1/jobs", s.handleListJobs).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/watch", s.handleWatchJobs).Methods(http.MethodGet)
 	r.HandleFunc("/v1/jobs/{jobID}", s.handleGetJob).Methods(http.MethodGet)
 	r.HandleFunc("/v1/jobs/{jobID}/cancel", s.handleCancelJob).Methods(http.MethodPost)
+	r.HandleFunc("/v1/jobs/{jobID}/attempts", s.handleListJobAttempts).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/{jobID}/events", s.handleListJobEvents).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/{jobID}/result", s.handleGetJobResult).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/{jobID}/logs", s.handleListJobLogs).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/{jobID}/watch", s.handleWatchJob).Methods(http.MethodGet)
+	r.HandleFunc("/v1/jobs/{jobID}/wait", s.handleWaitJob).Methods(http.MethodGet)
+
+	r.HandleFunc("/v1/events", s.handleListEvents).Methods(http.MethodGet)
+
+	r.HandleFunc("/v1/workflows", s.handleCreateWorkflow).Methods(http.MethodPost)
+	r.HandleFunc("/v1/workflows/{workflowID}", s.handleGetWorkflow).Methods(http.MethodGet)
+
+	r.HandleFunc("/v1/dead-letters", s.handleListDeadLetters).Methods(http.MethodGet)
+	r.HandleFunc("/v1/dead-letters/replay", s.handleReplayDeadLetters).Methods(http.MethodPost)
+	r.HandleFunc("/v1/dead-letters/purge", s.handlePurgeDeadLetters).Methods(http.MethodPost)
+	r.HandleFunc("/v1/dead-letters/{deadLetterID}", s.handleGetDeadLetter).Methods(http.MethodGet)
+
+	r.HandleFunc("/v1/webhooks", s.handleCreateWebhook).Methods(http.MethodPost)
+	r.HandleFunc("/v1/webhooks", s.handleListWebhooks).Methods(http.MethodGet)
+	r.HandleFunc("/v1/webhooks/{webhookID}", s.handleGetWebhook).Methods(http.MethodGet)
+	r.HandleFunc("/v1/webhooks/{webhookID}", s.handleDeleteWebhook).Methods(http.MethodDelete)
+	r.HandleFunc("/v1/webhooks/{webhookID}/deliveries", s.handleListWebhookDeliveries).Methods(http.MethodGet)
+	r.HandleFunc("/v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", s.handleRedeliverWebhookDelivery).Methods(http.MethodPost)
+
+	r.HandleFunc("/v1/queues", s.handleListQueues).Methods(http.MethodGet)
+	r.HandleFunc("/v1/queues/{queue}", s.handleSetQueue).Methods(http.MethodPut)
+
+	r.HandleFunc("/v1/schedules", s.handleCreateSchedule).Methods(http.MethodPost)
+	r.HandleFunc("/v1/schedules", s.handleListSchedules).Methods(http.MethodGet)
+	r.HandleFunc("/v1/schedules/{scheduleID}", s.handleGetSchedule).Methods(http.MethodGet)
+	r.HandleFunc("/v1/schedules/{scheduleID}", s.handleUpdateSchedule).Methods(http.MethodPut)
+	r.HandleFunc("/v1/schedules/{scheduleID}", s.handleDeleteSchedule).Methods(http.MethodDelete)
+	r.HandleFunc("/v1/schedules/{scheduleID}/pause", s.handlePauseSchedule).Methods(http.MethodPost)
+	r.HandleFunc("/v1/schedules/{scheduleID}/resume", s.handleResumeSchedule).Methods(http.MethodPost)
 
 	r.HandleFunc("/internal/jobs/lease", s.handleAcquireLease).Methods(http.MethodPost)
 	r.HandleFunc("/internal/jobs/recover", s.handleRecoverLeases).Methods(http.MethodPost)
 
+	r.HandleFunc("/internal/jobs/{jobID}/lease/renew", s.handleRenewLease).Methods(http.MethodPost)
 	r.HandleFunc("/internal/jobs/{jobID}/start", s.handleStartJob).Methods(http.MethodPost)
+	r.HandleFunc("/internal/jobs/{jobID}/progress", s.handleReportJobProgress).Methods(http.MethodPost)
 	r.HandleFunc("/internal/jobs/{jobID}/complete", s.handleCompleteJob).Methods(http.MethodPost)
 	r.HandleFunc("/internal/jobs/{jobID}/fail", s.handleFailJob).Methods(http.MethodPost)
 
 	r.HandleFunc("/internal/workers/{workerID}/heartbeat", s.handleWorkerHeartbeat).Methods(http.MethodPost)
+	r.HandleFunc("/internal/workers/{workerID}/recover", s.handleRecoverWorkerLeases).Methods(http.MethodPost)
 
 	s.mux = r
 }
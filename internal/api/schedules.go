@@ -0,0 +1,361 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/cron"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+var errInvalidSchedule = errors.New("invalid schedule parameters")
+
+// scheduleFromRequest validates a schedule request and computes the first
+// tick after now.
+func scheduleFromRequest(scheduleRequest ScheduleRequest, now time.Time) (store.Schedule, error) {
+	if scheduleRequest.Timezone == "" {
+		scheduleRequest.Timezone = "UTC"
+	}
+
+	if scheduleRequest.CatchUpPolicy == "" {
+		scheduleRequest.CatchUpPolicy = store.CatchUpSkip
+	}
+
+	switch scheduleRequest.CatchUpPolicy {
+	case store.CatchUpSkip, store.CatchUpRunOnce, store.CatchUpRunAll:
+	default:
+		return store.Schedule{}, errInvalidSchedule
+	}
+
+	if scheduleRequest.Name == "" ||
+		scheduleRequest.JobType == "" ||
+		scheduleRequest.MaxAttempts < 1 ||
+		scheduleRequest.TimeoutSeconds <= 0 {
+		return store.Schedule{}, errInvalidSchedule
+	}
+
+	cronSchedule, err := cron.Parse(scheduleRequest.CronExpression, scheduleRequest.Timezone)
+	if err != nil {
+		return store.Schedule{}, err
+	}
+
+	payloadBytes, err := json.Marshal(scheduleRequest.Payload)
+	if err != nil {
+		return store.Schedule{}, errInvalidSchedule
+	}
+
+	return store.Schedule{
+		Name:           scheduleRequest.Name,
+		CronExpression: scheduleRequest.CronExpression,
+		Timezone:       scheduleRequest.Timezone,
+		CatchUpPolicy:  scheduleRequest.CatchUpPolicy,
+		JobType:        scheduleRequest.JobType,
+		Payload:        payloadBytes,
+		MaxAttempts:    scheduleRequest.MaxAttempts,
+		TimeoutSeconds: scheduleRequest.TimeoutSeconds,
+		Paused:         scheduleRequest.Paused,
+		NextRunAt:      cronSchedule.Next(now),
+	}, nil
+}
+
+// @Summary Create a schedule
+// @Description Create a recurring schedule that creates a job on every cron tick
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param request body ScheduleRequest true "Schedule definition"
+// @Success 201 {object} ScheduleResponse
+// @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules [post]
+func (s *Server) handleCreateSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var scheduleRequest ScheduleRequest
+
+	if err := json.NewDecoder(request.Body).Decode(&scheduleRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := scheduleFromRequest(scheduleRequest, time.Now())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule.ID = uuid.New()
+
+	if err := s.store.CreateSchedule(request.Context(), schedule); err != nil {
+		if err == store.ErrScheduleNameTaken {
+			http.Error(writer, "Schedule name already in use", http.StatusConflict)
+			return
+		}
+
+		http.Error(writer, "Failed to create schedule", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeSchedule(writer, request, schedule.ID, http.StatusCreated)
+	LoggerFromContext(request.Context()).Info("schedule created", "schedule_id", schedule.ID.String(), "name", schedule.Name)
+}
+
+// @Summary List schedules
+// @Description List recurring schedules ordered by name
+// @Tags Schedules
+// @Produce json
+// @Param limit query int false "Maximum number of schedules (default 100)"
+// @Success 200 {object} ListSchedulesResponse
+// @Failure 500 {string} string
+// @Router /v1/schedules [get]
+func (s *Server) handleListSchedules(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	limit := 100
+	if rawLimit := request.URL.Query().Get("limit"); rawLimit != "" {
+		if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	schedules, err := s.store.ListSchedules(request.Context(), limit)
+	if err != nil {
+		http.Error(writer, "Failed to list schedules", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListSchedulesResponse{
+		Schedules: make([]ScheduleResponse, 0, len(schedules)),
+	}
+
+	for _, schedule := range schedules {
+		response.Schedules = append(response.Schedules, newScheduleResponse(schedule))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Get a schedule
+// @Description Fetch a recurring schedule and its next tick
+// @Tags Schedules
+// @Produce json
+// @Param scheduleID path string true "Schedule ID"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules/{scheduleID} [get]
+func (s *Server) handleGetSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	scheduleID, err := uuid.Parse(request.PathValue("scheduleID"))
+	if err != nil {
+		http.Error(writer, "Invalid schedule id", http.StatusBadRequest)
+		return
+	}
+
+	s.writeSchedule(writer, request, scheduleID, http.StatusOK)
+}
+
+// @Summary Update a schedule
+// @Description Replace a schedule definition. The next tick is recomputed from now, so missed ticks are not caught up.
+// @Tags Schedules
+// @Accept json
+// @Produce json
+// @Param scheduleID path string true "Schedule ID"
+// @Param request body ScheduleRequest true "Schedule definition"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules/{scheduleID} [put]
+func (s *Server) handleUpdateSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	scheduleID, err := uuid.Parse(request.PathValue("scheduleID"))
+	if err != nil {
+		http.Error(writer, "Invalid schedule id", http.StatusBadRequest)
+		return
+	}
+
+	var scheduleRequest ScheduleRequest
+
+	if err := json.NewDecoder(request.Body).Decode(&scheduleRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := scheduleFromRequest(scheduleRequest, time.Now())
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule.ID = scheduleID
+
+	if err := s.store.UpdateSchedule(request.Context(), schedule); err != nil {
+		switch err {
+		case store.ErrScheduleNotFound:
+			http.Error(writer, "Schedule not found", http.StatusNotFound)
+		case store.ErrScheduleNameTaken:
+			http.Error(writer, "Schedule name already in use", http.StatusConflict)
+		default:
+			http.Error(writer, "Failed to update schedule", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	s.writeSchedule(writer, request, scheduleID, http.StatusOK)
+	LoggerFromContext(request.Context()).Info("schedule updated", "schedule_id", scheduleID.String())
+}
+
+// @Summary Delete a schedule
+// @Description Delete a schedule. Jobs it already created are kept.
+// @Tags Schedules
+// @Param scheduleID path string true "Schedule ID"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules/{scheduleID} [delete]
+func (s *Server) handleDeleteSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	scheduleID, err := uuid.Parse(request.PathValue("scheduleID"))
+	if err != nil {
+		http.Error(writer, "Invalid schedule id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.DeleteSchedule(request.Context(), scheduleID); err != nil {
+		if err == store.ErrScheduleNotFound {
+			http.Error(writer, "Schedule not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(writer, "Failed to delete schedule", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("schedule deleted", "schedule_id", scheduleID.String())
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Pause a schedule
+// @Description Stop a schedule from creating jobs until it is resumed
+// @Tags Schedules
+// @Produce json
+// @Param scheduleID path string true "Schedule ID"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules/{scheduleID}/pause [post]
+func (s *Server) handlePauseSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	scheduleID, err := uuid.Parse(request.PathValue("scheduleID"))
+	if err != nil {
+		http.Error(writer, "Invalid schedule id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.SetSchedulePaused(request.Context(), scheduleID, true, nil); err != nil {
+		if err == store.ErrScheduleNotFound {
+			http.Error(writer, "Schedule not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(writer, "Failed to pause schedule", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeSchedule(writer, request, scheduleID, http.StatusOK)
+	LoggerFromContext(request.Context()).Info("schedule paused", "schedule_id", scheduleID.String())
+}
+
+// @Summary Resume a schedule
+// @Description Resume a paused schedule from its next tick. Ticks missed while paused are not caught up.
+// @Tags Schedules
+// @Produce json
+// @Param scheduleID path string true "Schedule ID"
+// @Success 200 {object} ScheduleResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/schedules/{scheduleID}/resume [post]
+func (s *Server) handleResumeSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	scheduleID, err := uuid.Parse(request.PathValue("scheduleID"))
+	if err != nil {
+		http.Error(writer, "Invalid schedule id", http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := s.store.GetScheduleByID(request.Context(), scheduleID)
+	if err != nil {
+		http.Error(writer, "Failed to resume schedule", http.StatusInternalServerError)
+		return
+	}
+	if schedule == nil {
+		http.Error(writer, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	cronSchedule, err := cron.Parse(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		http.Error(writer, "Failed to resume schedule", http.StatusInternalServerError)
+		return
+	}
+
+	nextRunAt := cronSchedule.Next(time.Now())
+
+	if err := s.store.SetSchedulePaused(request.Context(), scheduleID, false, &nextRunAt); err != nil {
+		if err == store.ErrScheduleNotFound {
+			http.Error(writer, "Schedule not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(writer, "Failed to resume schedule", http.StatusInternalServerError)
+		return
+	}
+
+	s.writeSchedule(writer, request, scheduleID, http.StatusOK)
+	LoggerFromContext(request.Context()).Info("schedule resumed", "schedule_id", scheduleID.String())
+}
+
+func (s *Server) writeSchedule(
+	writer http.ResponseWriter,
+	request *http.Request,
+	scheduleID uuid.UUID,
+	status int,
+) {
+	schedule, err := s.store.GetScheduleByID(request.Context(), scheduleID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch schedule", http.StatusInternalServerError)
+		return
+	}
+	if schedule == nil {
+		http.Error(writer, "Schedule not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(newScheduleResponse(*schedule))
+}
@@ -4,26 +4,67 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/vin-jex/job-orchestrator/internal/observability"
 	"github.com/vin-jex/job-orchestrator/internal/store"
+	"github.com/vin-jex/job-orchestrator/internal/webhooks"
 )
 
+// DefaultIdempotencyKeyRetention is how long an Idempotency-Key is
+// remembered when Config leaves it unset.
+const DefaultIdempotencyKeyRetention = 24 * time.Hour
+
+// Config tunes the API server. Zero values select the defaults.
+type Config struct {
+	IdempotencyKeyRetention time.Duration
+
+	// JobResultMaxBytes caps the encoded size of a result reported on
+	// completion, and JobResultRetention is how long results are kept.
+	JobResultMaxBytes  int
+	JobResultRetention time.Duration
+
+	// WebhookAllowedNetworks are internal networks that webhooks may be
+	// registered for; every other internal address is refused.
+	WebhookAllowedNetworks []netip.Prefix
+}
+
 type Server struct {
 	store  *store.Store
 	mux    *mux.Router
 	logger *slog.Logger
+	config Config
+	hub    *jobEventHub
+
+	webhookGuard *webhooks.TargetGuard
 }
 
 type loggerKey struct{}
 
-func NewServer(storeLayer *store.Store, logger *slog.Logger) *Server {
+func NewServer(storeLayer *store.Store, logger *slog.Logger, config Config) *Server {
+	if config.IdempotencyKeyRetention <= 0 {
+		config.IdempotencyKeyRetention = DefaultIdempotencyKeyRetention
+	}
+
+	if config.JobResultMaxBytes <= 0 {
+		config.JobResultMaxBytes = store.DefaultJobResultMaxBytes
+	}
+
+	if config.JobResultRetention <= 0 {
+		config.JobResultRetention = store.DefaultJobResultRetention
+	}
+
 	server := &Server{
 		store:  storeLayer,
 		mux:    mux.NewRouter(),
 		logger: logger,
+		config: config,
+		hub:    newJobEventHub(),
+
+		webhookGuard: webhooks.NewTargetGuard(config.WebhookAllowedNetworks),
 	}
 
 	server.registerRoutes()
@@ -49,6 +90,7 @@ func (s *Server) withRequestID(next http.Handler) http.Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), observability.RequestIDKey(), requestID)
+		ctx = store.WithActor(ctx, "request:"+requestID)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+const (
+	// defaultJobWait is how long GET /v1/jobs/{jobID}/wait blocks when no
+	// timeout is given.
+	defaultJobWait = 30 * time.Second
+
+	// maxJobWait bounds how long a request may wait for a job.
+	maxJobWait = 60 * time.Second
+)
+
+var errInvalidWaitTimeout = errors.New("invalid wait timeout")
+
+// parseWaitTimeout accepts a Go duration such as "30s" or a plain number of
+// seconds, capped at maxJobWait.
+func parseWaitTimeout(raw string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(raw)
+	if err != nil {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil {
+			return 0, errInvalidWaitTimeout
+		}
+		timeout = time.Duration(seconds) * time.Second
+	}
+
+	if timeout <= 0 {
+		return 0, errInvalidWaitTimeout
+	}
+
+	return min(timeout, maxJobWait), nil
+}
+
+// extendWriteDeadline keeps the server's write timeout from cutting a
+// waiting request short.
+func extendWriteDeadline(writer http.ResponseWriter, wait time.Duration) {
+	_ = http.NewResponseController(writer).SetWriteDeadline(time.Now().Add(wait + 10*time.Second))
+}
+
+// waitForJob blocks until the job reaches a terminal state, timeout passes
+// or ctx is done, then returns the job as it is at that point. It waits on
+// job event notifications, so a waiting request holds no database
+// connection. It returns nil when the job does not exist.
+func (s *Server) waitForJob(
+	ctx context.Context,
+	jobID uuid.UUID,
+	timeout time.Duration,
+) (*store.Job, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	match := func(event store.JobEventNotification) bool {
+		return event.JobID == jobID && store.IsTerminalState(event.ToState)
+	}
+
+	for {
+		// Subscribing before reading the job guarantees that a terminal
+		// transition cannot fall between the two.
+		subscription := s.hub.subscribe(match)
+
+		job, err := s.store.GetJobByID(ctx, jobID)
+		if err != nil || job == nil || store.IsTerminalState(job.State) {
+			s.hub.unsubscribe(subscription)
+			return job, err
+		}
+
+		// A closed subscription was dropped, possibly with the terminal
+		// transition, so the job is read again under a new one.
+		select {
+		case _, ok := <-subscription.events:
+			s.hub.unsubscribe(subscription)
+			if ok {
+				return s.store.GetJobByID(ctx, jobID)
+			}
+		case <-timer.C:
+			s.hub.unsubscribe(subscription)
+			return s.store.GetJobByID(ctx, jobID)
+		case <-ctx.Done():
+			s.hub.unsubscribe(subscription)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// @Summary Wait for a job
+// @Description Block until the job reaches a terminal state or the timeout passes, then return the job. A job that is still unfinished when the timeout passes is returned as is.
+// @Tags Jobs
+// @Produce json
+// @Param jobID path string true "Job ID"
+// @Param timeout query string false "How long to wait, as a duration (30s) or seconds (default 30s, max 60s)"
+// @Success 200 {object} JobResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/wait [get]
+func (s *Server) handleWaitJob(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	timeout := defaultJobWait
+	if rawTimeout := request.URL.Query().Get("timeout"); rawTimeout != "" {
+		timeout, err = parseWaitTimeout(rawTimeout)
+		if err != nil {
+			http.Error(writer, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+	}
+
+	extendWriteDeadline(writer, timeout)
+
+	job, err := s.waitForJob(request.Context(), jobID, timeout)
+	if err != nil {
+		if request.Context().Err() != nil {
+			return
+		}
+
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(newJobResponse(*job))
+}
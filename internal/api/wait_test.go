@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// testJobPriority lies far above the priority of any job other tests leave
+// behind, so that the job of a test is the one leased.
+const testJobPriority = 1_000_000_000
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Fatal("TEST_DATABASE_URL is required")
+	}
+
+	storeLayer, err := store.NewStore(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(storeLayer.Close)
+
+	return NewServer(storeLayer, slog.New(slog.NewTextHandler(io.Discard, nil)), Config{})
+}
+
+// createTestJob creates a PENDING job in a queue of its own. It is cancelled
+// when the test ends.
+func createTestJob(t *testing.T, server *Server) store.JobSpec {
+	t.Helper()
+
+	spec := store.JobSpec{
+		ID:             uuid.New(),
+		Type:           "wait-test",
+		Payload:        []byte(`{}`),
+		MaxAttempts:    1,
+		TimeoutSeconds: 30,
+		Priority:       testJobPriority,
+		Queue:          "wait-" + uuid.NewString(),
+	}
+
+	if _, err := server.store.CreateJob(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = server.store.CancelJob(context.Background(), spec.ID)
+	})
+
+	return spec
+}
+
+// completeTestJob runs the job of spec to completion as a worker would and
+// publishes the completion as the event listener would.
+func completeTestJob(t *testing.T, server *Server, spec store.JobSpec) {
+	t.Helper()
+
+	ctx := context.Background()
+	workerID := uuid.New()
+
+	if err := server.store.RegisterWorker(ctx, workerID, 1, []string{spec.Queue}); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := server.store.AcquireJobLease(ctx, uuid.New(), store.DefaultLeaseDuration); err != nil {
+		t.Error(err)
+		return
+	}
+
+	job, lease, err := server.store.AcquireScheduledJobForWorker(ctx, workerID, []string{spec.Type}, []string{spec.Queue})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := server.store.CompleteJob(ctx, job.ID, lease.FencingToken, nil); err != nil {
+		t.Error(err)
+		return
+	}
+
+	server.hub.publish(store.JobEventNotification{
+		JobID:   job.ID,
+		JobType: job.Type,
+		Queue:   job.Queue,
+		ToState: store.JobCompleted,
+	})
+}
+
+func TestWaitForJobReturnsOnCompletion(t *testing.T) {
+	server := newTestServer(t)
+	spec := createTestJob(t, server)
+
+	completed := make(chan struct{})
+	go func() {
+		defer close(completed)
+		completeTestJob(t, server, spec)
+	}()
+
+	started := time.Now()
+
+	job, err := server.waitForJob(context.Background(), spec.ID, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-completed
+
+	if job.State != store.JobCompleted {
+		t.Fatalf("expected the job to be completed, got %s", job.State)
+	}
+	if time.Since(started) > 5*time.Second {
+		t.Fatalf("expected the wait to end on completion, took %s", time.Since(started))
+	}
+}
+
+func TestWaitForJobTimesOutWithCurrentState(t *testing.T) {
+	server := newTestServer(t)
+	spec := createTestJob(t, server)
+
+	request := httptest.NewRequest(http.MethodGet, "/v1/jobs/wait?timeout=200ms", nil)
+	request.SetPathValue("jobID", spec.ID.String())
+	recorder := httptest.NewRecorder()
+
+	server.handleWaitJob(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	var response JobResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.State != store.JobPending {
+		t.Fatalf("expected the unfinished job to be returned as %s, got %s", store.JobPending, response.State)
+	}
+}
+
+func TestWaitForTerminalJobReturnsAtOnce(t *testing.T) {
+	server := newTestServer(t)
+	spec := createTestJob(t, server)
+
+	if err := server.store.CancelJob(context.Background(), spec.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	started := time.Now()
+
+	job, err := server.waitForJob(context.Background(), spec.ID, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.State != store.JobCancelled {
+		t.Fatalf("expected the job to be cancelled, got %s", job.State)
+	}
+	if time.Since(started) > time.Second {
+		t.Fatalf("expected the wait to return at once, took %s", time.Since(started))
+	}
+}
@@ -0,0 +1,312 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+const (
+	// watchBufferSize is how many events a watcher may fall behind before
+	// its stream is closed.
+	watchBufferSize = 64
+
+	// watchKeepAliveInterval is how often an idle stream sends a comment so
+	// that proxies keep it open.
+	watchKeepAliveInterval = 15 * time.Second
+
+	// listenRetryDelay is how long RunEventListener waits before
+	// reconnecting after losing its connection.
+	listenRetryDelay = time.Second
+)
+
+// jobEventHub fans job event notifications out to the watchers of this
+// process.
+type jobEventHub struct {
+	mu          sync.Mutex
+	subscribers map[*jobEventSubscription]struct{}
+}
+
+type jobEventSubscription struct {
+	match  func(store.JobEventNotification) bool
+	events chan store.JobEventNotification
+}
+
+func newJobEventHub() *jobEventHub {
+	return &jobEventHub{
+		subscribers: make(map[*jobEventSubscription]struct{}),
+	}
+}
+
+func (h *jobEventHub) subscribe(match func(store.JobEventNotification) bool) *jobEventSubscription {
+	subscription := &jobEventSubscription{
+		match:  match,
+		events: make(chan store.JobEventNotification, watchBufferSize),
+	}
+
+	h.mu.Lock()
+	h.subscribers[subscription] = struct{}{}
+	h.mu.Unlock()
+
+	return subscription
+}
+
+func (h *jobEventHub) unsubscribe(subscription *jobEventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[subscription]; ok {
+		delete(h.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// closeAll ends every subscription. Notifications may have been missed, so
+// watchers must read the current state again.
+func (h *jobEventHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers {
+		delete(h.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// publish never blocks: a watcher whose buffer is full is dropped, and its
+// stream ends so that the client reconnects and reads the current state.
+func (h *jobEventHub) publish(event store.JobEventNotification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers {
+		if !subscription.match(event) {
+			continue
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			delete(h.subscribers, subscription)
+			close(subscription.events)
+		}
+	}
+}
+
+// RunEventListener feeds job event notifications from Postgres to the
+// watch endpoints until ctx is done, reconnecting whenever the listening
+// connection is lost. Every subscription is closed when the connection is
+// lost and again once it is back, since notifications sent in between are
+// never delivered; watchers then read the current state again.
+func (s *Server) RunEventListener(ctx context.Context) {
+	for {
+		err := s.store.ListenJobEvents(ctx, s.hub.closeAll, s.hub.publish)
+		s.hub.closeAll()
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("job event listener stopped", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// startEventStream prepares writer for a Server-Sent Events stream. The
+// server's write timeout would otherwise cut the stream short.
+func startEventStream(writer http.ResponseWriter) *http.ResponseController {
+	controller := http.NewResponseController(writer)
+	_ = controller.SetWriteDeadline(time.Time{})
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+
+	return controller
+}
+
+func writeServerSentEvent(
+	writer http.ResponseWriter,
+	controller *http.ResponseController,
+	event string,
+	data any,
+) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, encoded); err != nil {
+		return err
+	}
+
+	return controller.Flush()
+}
+
+func newTransitionResponse(event store.JobEventNotification) JobTransitionResponse {
+	return JobTransitionResponse{
+		EventID:   event.EventID,
+		JobID:     event.JobID.String(),
+		JobType:   event.JobType,
+		Queue:     event.Queue,
+		FromState: event.FromState,
+		ToState:   event.ToState,
+		Actor:     event.Actor,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// streamTransitions writes every event of subscription until the client
+// goes away, the subscription is dropped, or done reports that the stream
+// is finished.
+func streamTransitions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	controller *http.ResponseController,
+	subscription *jobEventSubscription,
+	skip func(store.JobEventNotification) bool,
+	done func(store.JobEventNotification) bool,
+) {
+	keepAlive := time.NewTicker(watchKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+
+		case event, ok := <-subscription.events:
+			if !ok {
+				return
+			}
+			if skip(event) {
+				continue
+			}
+
+			if err := writeServerSentEvent(writer, controller, "transition", newTransitionResponse(event)); err != nil {
+				return
+			}
+
+			if done(event) {
+				return
+			}
+		}
+	}
+}
+
+// @Summary Watch a job
+// @Description Stream the job's state changes as Server-Sent Events. The stream starts with a "state" event carrying the current job, followed by a "transition" event for every state change, and ends after the job reaches a terminal state.
+// @Tags Jobs
+// @Produce text/event-stream
+// @Param jobID path string true "Job ID"
+// @Success 200 {object} JobTransitionResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/jobs/{jobID}/watch [get]
+func (s *Server) handleWatchJob(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	jobID, err := uuid.Parse(request.PathValue("jobID"))
+	if err != nil {
+		http.Error(writer, "Invalid job id", http.StatusBadRequest)
+		return
+	}
+
+	// Subscribing before reading the job guarantees that no transition
+	// falls between the two.
+	subscription := s.hub.subscribe(func(event store.JobEventNotification) bool {
+		return event.JobID == jobID
+	})
+	defer s.hub.unsubscribe(subscription)
+
+	job, latestEventID, err := s.store.GetJobForWatch(request.Context(), jobID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch job", http.StatusInternalServerError)
+		return
+	}
+	if job == nil {
+		http.Error(writer, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	controller := startEventStream(writer)
+
+	if err := writeServerSentEvent(writer, controller, "state", newJobResponse(*job)); err != nil {
+		return
+	}
+
+	if store.IsTerminalState(job.State) {
+		return
+	}
+
+	streamTransitions(
+		writer,
+		request,
+		controller,
+		subscription,
+		func(event store.JobEventNotification) bool {
+			return event.EventID <= latestEventID
+		},
+		func(event store.JobEventNotification) bool {
+			return store.IsTerminalState(event.ToState)
+		},
+	)
+}
+
+// @Summary Watch jobs
+// @Description Stream state changes of all jobs, optionally narrowed to a job type or queue, as Server-Sent "transition" events. Changes made while the client is disconnected are not replayed; use /v1/events to catch up.
+// @Tags Jobs
+// @Produce text/event-stream
+// @Param job_type query string false "Only jobs of this type"
+// @Param queue query string false "Only jobs in this queue"
+// @Success 200 {object} JobTransitionResponse
+// @Router /v1/jobs/watch [get]
+func (s *Server) handleWatchJobs(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	query := request.URL.Query()
+	jobType := query.Get("job_type")
+	queue := query.Get("queue")
+
+	subscription := s.hub.subscribe(func(event store.JobEventNotification) bool {
+		return (jobType == "" || event.JobType == jobType) &&
+			(queue == "" || event.Queue == queue)
+	})
+	defer s.hub.unsubscribe(subscription)
+
+	controller := startEventStream(writer)
+
+	// An empty comment commits the response so that clients see the stream
+	// open before the first transition.
+	if _, err := fmt.Fprint(writer, ": watching\n\n"); err != nil {
+		return
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	never := func(store.JobEventNotification) bool { return false }
+
+	streamTransitions(writer, request, controller, subscription, never, never)
+}
@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+var errInvalidWebhook = errors.New("invalid webhook parameters")
+
+// webhookFromRequest validates a webhook request, generating a secret when
+// none is given. The URL must not point into the orchestrator's own network.
+func (s *Server) webhookFromRequest(
+	ctx context.Context,
+	webhookRequest CreateWebhookRequest,
+) (store.Webhook, error) {
+	target, err := url.Parse(webhookRequest.URL)
+	if err != nil ||
+		(target.Scheme != "http" && target.Scheme != "https") ||
+		target.Host == "" {
+		return store.Webhook{}, errors.New("invalid webhook url")
+	}
+
+	if err := s.webhookGuard.CheckURL(ctx, webhookRequest.URL); err != nil {
+		return store.Webhook{}, err
+	}
+
+	for _, state := range webhookRequest.States {
+		if !store.IsJobState(state) {
+			return store.Webhook{}, errInvalidWebhook
+		}
+	}
+
+	secret := webhookRequest.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return store.Webhook{}, err
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	webhook := store.Webhook{
+		URL:    webhookRequest.URL,
+		Secret: secret,
+		States: webhookRequest.States,
+	}
+
+	if webhookRequest.JobType != "" {
+		webhook.JobType = &webhookRequest.JobType
+	}
+
+	if webhookRequest.Queue != "" {
+		webhook.Queue = &webhookRequest.Queue
+	}
+
+	return webhook, nil
+}
+
+// @Summary Create a webhook
+// @Description Subscribe a URL to job state changes, optionally filtered by job type, queue and target states. Every matching event is POSTed as JSON, signed in the X-Webhook-Signature header with the HMAC-SHA256 of "<X-Webhook-Timestamp>.<body>" keyed with the secret. Failed deliveries are retried with backoff. The secret is only returned here. URLs resolving to loopback, private, link-local or other special-purpose addresses are rejected unless allowed by WEBHOOK_ALLOWED_NETWORKS.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body CreateWebhookRequest true "Webhook subscription"
+// @Success 201 {object} CreateWebhookResponse
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /v1/webhooks [post]
+func (s *Server) handleCreateWebhook(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var webhookRequest CreateWebhookRequest
+
+	if err := json.NewDecoder(request.Body).Decode(&webhookRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := s.webhookFromRequest(request.Context(), webhookRequest)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	webhook.ID = uuid.New()
+
+	if err := s.store.CreateWebhook(request.Context(), webhook); err != nil {
+		http.Error(writer, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+
+	created, err := s.store.GetWebhookByID(request.Context(), webhook.ID)
+	if err != nil || created == nil {
+		http.Error(writer, "Failed to fetch webhook", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("webhook created", "webhook_id", webhook.ID.String())
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(writer).Encode(CreateWebhookResponse{
+		WebhookResponse: newWebhookResponse(*created),
+		Secret:          created.Secret,
+	})
+}
+
+// @Summary List webhooks
+// @Description List webhook subscriptions in creation order
+// @Tags Webhooks
+// @Produce json
+// @Success 200 {object} ListWebhooksResponse
+// @Failure 500 {string} string
+// @Router /v1/webhooks [get]
+func (s *Server) handleListWebhooks(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	webhooks, err := s.store.ListWebhooks(request.Context())
+	if err != nil {
+		http.Error(writer, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListWebhooksResponse{
+		Webhooks: make([]WebhookResponse, 0, len(webhooks)),
+	}
+
+	for _, webhook := range webhooks {
+		response.Webhooks = append(response.Webhooks, newWebhookResponse(webhook))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Get a webhook
+// @Tags Webhooks
+// @Produce json
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/webhooks/{webhookID} [get]
+func (s *Server) handleGetWebhook(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	webhookID, err := uuid.Parse(request.PathValue("webhookID"))
+	if err != nil {
+		http.Error(writer, "Invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := s.store.GetWebhookByID(request.Context(), webhookID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch webhook", http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		http.Error(writer, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(newWebhookResponse(*webhook))
+}
+
+// @Summary Delete a webhook
+// @Description Delete a webhook subscription along with its delivery log
+// @Tags Webhooks
+// @Param webhookID path string true "Webhook ID"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/webhooks/{webhookID} [delete]
+func (s *Server) handleDeleteWebhook(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	webhookID, err := uuid.Parse(request.PathValue("webhookID"))
+	if err != nil {
+		http.Error(writer, "Invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.DeleteWebhook(request.Context(), webhookID); err != nil {
+		if err == store.ErrWebhookNotFound {
+			http.Error(writer, "Webhook not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(writer, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("webhook deleted", "webhook_id", webhookID.String())
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description Return the delivery log of a webhook, newest first
+// @Tags Webhooks
+// @Produce json
+// @Param webhookID path string true "Webhook ID"
+// @Param limit query int false "Maximum number of deliveries (default 100)"
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/webhooks/{webhookID}/deliveries [get]
+func (s *Server) handleListWebhookDeliveries(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	webhookID, err := uuid.Parse(request.PathValue("webhookID"))
+	if err != nil {
+		http.Error(writer, "Invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := s.store.GetWebhookByID(request.Context(), webhookID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch webhook", http.StatusInternalServerError)
+		return
+	}
+	if webhook == nil {
+		http.Error(writer, "Webhook not found", http.StatusNotFound)
+		return
+	}
+
+	limit := 100
+	if rawLimit := request.URL.Query().Get("limit"); rawLimit != "" {
+		if parsed, err := strconv.Atoi(rawLimit); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(request.Context(), webhookID, limit)
+	if err != nil {
+		http.Error(writer, "Failed to list webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	response := ListWebhookDeliveriesResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+	}
+
+	for _, delivery := range deliveries {
+		response.Deliveries = append(response.Deliveries, newWebhookDeliveryResponse(delivery))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Redeliver a webhook delivery
+// @Description Queue a delivery to be sent again right away with a fresh retry budget, whatever its current state
+// @Tags Webhooks
+// @Produce json
+// @Param webhookID path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 202 {object} WebhookDeliveryResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func (s *Server) handleRedeliverWebhookDelivery(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	webhookID, err := uuid.Parse(request.PathValue("webhookID"))
+	if err != nil {
+		http.Error(writer, "Invalid webhook id", http.StatusBadRequest)
+		return
+	}
+
+	deliveryID, err := uuid.Parse(request.PathValue("deliveryID"))
+	if err != nil {
+		http.Error(writer, "Invalid delivery id", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := s.store.RedeliverWebhookDelivery(request.Context(), webhookID, deliveryID)
+	if err != nil {
+		if err == store.ErrWebhookDeliveryNotFound {
+			http.Error(writer, "Webhook delivery not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(writer, "Failed to redeliver webhook delivery", http.StatusInternalServerError)
+		return
+	}
+	LoggerFromContext(request.Context()).Info("webhook delivery requeued", "delivery_id", deliveryID.String())
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(writer).Encode(newWebhookDeliveryResponse(*delivery))
+}
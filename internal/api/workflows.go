@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// @Summary Create a workflow
+// @Description Create a set of jobs connected by depends_on edges. Jobs with dependencies stay BLOCKED until every job they depend on has COMPLETED. If a dependency fails or is cancelled, its blocked dependents are failed (failure_policy FAIL) or cancelled (CANCEL, the default).
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param request body CreateWorkflowRequest true "Workflow definition"
+// @Success 201 {object} CreateWorkflowResponse
+// @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /v1/workflows [post]
+func (s *Server) handleCreateWorkflow(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	var workflowRequest CreateWorkflowRequest
+
+	if err := json.NewDecoder(request.Body).Decode(&workflowRequest); err != nil {
+		http.Error(writer, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+
+	if workflowRequest.FailurePolicy == "" {
+		workflowRequest.FailurePolicy = store.WorkflowCancelDependents
+	}
+
+	if workflowRequest.Name == "" ||
+		len(workflowRequest.Jobs) == 0 ||
+		(workflowRequest.FailurePolicy != store.WorkflowFailDependents &&
+			workflowRequest.FailurePolicy != store.WorkflowCancelDependents) {
+		http.Error(writer, "invalid workflow parameters", http.StatusBadRequest)
+		return
+	}
+
+	jobIDs := make(map[string]uuid.UUID, len(workflowRequest.Jobs))
+	for _, jobRequest := range workflowRequest.Jobs {
+		if _, ok := jobIDs[jobRequest.Key]; ok || jobRequest.Key == "" {
+			http.Error(writer, "workflow job keys must be unique and non-empty", http.StatusBadRequest)
+			return
+		}
+		jobIDs[jobRequest.Key] = uuid.New()
+	}
+
+	workflowID := uuid.New()
+	now := time.Now()
+	jobs := make([]store.WorkflowJob, 0, len(workflowRequest.Jobs))
+	response := CreateWorkflowResponse{
+		WorkflowID: workflowID.String(),
+		Jobs:       make([]CreateWorkflowJobResponse, 0, len(workflowRequest.Jobs)),
+	}
+
+	for _, jobRequest := range workflowRequest.Jobs {
+		spec, err := jobSpecFromRequest(jobRequest.CreateJobRequest, jobIDs[jobRequest.Key], now)
+		if err != nil {
+			http.Error(writer, jobRequest.Key+": "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		workflowJob := store.WorkflowJob{Spec: spec}
+		for _, key := range jobRequest.DependsOn {
+			dependsOn, ok := jobIDs[key]
+			if !ok {
+				http.Error(writer, jobRequest.Key+": unknown dependency "+key, http.StatusBadRequest)
+				return
+			}
+			workflowJob.DependsOn = append(workflowJob.DependsOn, dependsOn)
+		}
+		jobs = append(jobs, workflowJob)
+
+		state := store.JobPending
+		if len(workflowJob.DependsOn) > 0 {
+			state = store.JobBlocked
+		}
+		response.Jobs = append(response.Jobs, CreateWorkflowJobResponse{
+			Key:   jobRequest.Key,
+			JobID: spec.ID.String(),
+			State: state,
+		})
+	}
+
+	err := s.store.CreateWorkflow(request.Context(), store.Workflow{
+		ID:            workflowID,
+		Name:          workflowRequest.Name,
+		FailurePolicy: workflowRequest.FailurePolicy,
+	}, jobs)
+	if err != nil {
+		if err == store.ErrWorkflowCycle || err == store.ErrWorkflowUnknownDependency {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err == store.ErrUniqueJobExists {
+			http.Error(writer, "A workflow job's unique_key is held by an unfinished job", http.StatusConflict)
+			return
+		}
+
+		http.Error(writer, "failed to create workflow", http.StatusInternalServerError)
+		return
+	}
+
+	LoggerFromContext(request.Context()).Info("workflow created", "workflow_id", response.WorkflowID, "jobs", len(jobs))
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(writer).Encode(response)
+}
+
+// @Summary Get a workflow
+// @Description Fetch a workflow with the state and dependencies of each of its jobs
+// @Tags Workflows
+// @Produce json
+// @Param workflowID path string true "Workflow ID"
+// @Success 200 {object} WorkflowResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /v1/workflows/{workflowID} [get]
+func (s *Server) handleGetWorkflow(
+	writer http.ResponseWriter,
+	request *http.Request,
+) {
+	workflowID, err := uuid.Parse(request.PathValue("workflowID"))
+	if err != nil {
+		http.Error(writer, "Invalid workflow id", http.StatusBadRequest)
+		return
+	}
+
+	workflow, err := s.store.GetWorkflowByID(request.Context(), workflowID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch workflow", http.StatusInternalServerError)
+		return
+	}
+	if workflow == nil {
+		http.Error(writer, "Workflow not found", http.StatusNotFound)
+		return
+	}
+
+	jobs, dependsOn, err := s.store.ListWorkflowJobs(request.Context(), workflowID)
+	if err != nil {
+		http.Error(writer, "Failed to fetch workflow", http.StatusInternalServerError)
+		return
+	}
+
+	response := WorkflowResponse{
+		WorkflowID:    workflow.ID.String(),
+		Name:          workflow.Name,
+		FailurePolicy: workflow.FailurePolicy,
+		CreatedAt:     workflow.CreatedAt,
+		Jobs:          make([]WorkflowJobResponse, 0, len(jobs)),
+	}
+
+	for _, job := range jobs {
+		jobResponse := WorkflowJobResponse{
+			JobResponse: newJobResponse(job),
+			DependsOn:   make([]string, 0, len(dependsOn[job.ID])),
+		}
+		for _, parentID := range dependsOn[job.ID] {
+			jobResponse.DependsOn = append(jobResponse.DependsOn, parentID.String())
+		}
+
+		response.Jobs = append(response.Jobs, jobResponse)
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(writer).Encode(response)
+}
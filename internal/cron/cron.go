@@ -0,0 +1,44 @@
+// Package cron parses the cron expressions of recurring schedules. It is
+// shared by the API, which validates schedules, and the scheduler, which
+// fires them.
+package cron
+
+import (
+	"errors"
+	"time"
+
+	robfig "github.com/robfig/cron/v3"
+)
+
+var ErrInvalidSchedule = errors.New("invalid cron schedule")
+
+var parser = robfig.NewParser(
+	robfig.Minute | robfig.Hour | robfig.Dom | robfig.Month | robfig.Dow | robfig.Descriptor,
+)
+
+// Schedule is a parsed cron expression bound to a timezone.
+type Schedule struct {
+	schedule robfig.Schedule
+	location *time.Location
+}
+
+// Parse parses a standard five field cron expression (or a descriptor such
+// as @hourly) evaluated in the given IANA timezone.
+func Parse(expression string, timezone string) (*Schedule, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+
+	schedule, err := parser.Parse(expression)
+	if err != nil {
+		return nil, ErrInvalidSchedule
+	}
+
+	return &Schedule{schedule: schedule, location: location}, nil
+}
+
+// Next returns the first tick strictly after the given time.
+func (c *Schedule) Next(after time.Time) time.Time {
+	return c.schedule.Next(after.In(c.location)).UTC()
+}
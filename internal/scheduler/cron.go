@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vin-jex/job-orchestrator/internal/cron"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+const (
+	// dueSchedulesBatchSize bounds how many schedules a single transaction
+	// locks and fires.
+	dueSchedulesBatchSize = 50
+
+	// maxCatchUpRuns bounds how many missed ticks a RUN_ALL schedule fires
+	// per pass. The rest are picked up on the following passes.
+	maxCatchUpRuns = 100
+
+	// skipGracePeriod is how late a tick may be handled under the SKIP
+	// policy and still count as on time.
+	skipGracePeriod = time.Minute
+)
+
+// planTicks returns the ticks to fire for a schedule whose next tick is due,
+// and the tick the schedule should wait for afterwards. Only RUN_ALL walks
+// the missed ticks one by one, at most maxCatchUpRuns of them per pass; the
+// other policies find the latest missed tick directly.
+func planTicks(
+	cronSchedule *cron.Schedule,
+	catchUpPolicy string,
+	nextRunAt time.Time,
+	now time.Time,
+) ([]time.Time, time.Time) {
+	if nextRunAt.After(now) {
+		return nil, nextRunAt
+	}
+
+	if catchUpPolicy == store.CatchUpRunAll {
+		var due []time.Time
+
+		tick := nextRunAt
+		for !tick.After(now) && len(due) < maxCatchUpRuns {
+			due = append(due, tick)
+			tick = cronSchedule.Next(tick)
+		}
+
+		return due, tick
+	}
+
+	latest := latestTick(cronSchedule, nextRunAt, now)
+	next := cronSchedule.Next(now)
+
+	if catchUpPolicy == store.CatchUpRunOnce || now.Sub(latest) <= skipGracePeriod {
+		return []time.Time{latest}, next
+	}
+
+	return nil, next
+}
+
+// latestTick returns the last tick in [from, now], where from is a tick no
+// later than now. It searches windows ending at now that double in length,
+// so a schedule that was due for a long time is not walked tick by tick.
+func latestTick(cronSchedule *cron.Schedule, from time.Time, now time.Time) time.Time {
+	latest := from
+
+	for window := time.Minute; ; window *= 2 {
+		start := from
+		if window > 0 && now.Add(-window).After(from) {
+			start = now.Add(-window)
+		}
+
+		for tick := cronSchedule.Next(start); !tick.IsZero() && !tick.After(now); tick = cronSchedule.Next(tick) {
+			latest = tick
+		}
+
+		if latest.After(from) || start.Equal(from) {
+			return latest
+		}
+	}
+}
+
+// fireDueSchedules creates one job for every due tick. Each schedule is
+// locked and advanced in the same transaction that creates its jobs, so
+// concurrent schedulers never fire the same tick twice. Each schedule fires
+// under its own savepoint, so one that fails is paused without rolling back
+// the rest of the batch.
+func (s *Scheduler) fireDueSchedules(ctx context.Context) {
+	now := time.Now().UTC()
+
+	err := s.store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		schedules, err := s.store.LockDueSchedules(ctx, tx, now, dueSchedulesBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			savepoint, err := tx.Begin(ctx)
+			if err != nil {
+				return err
+			}
+
+			ticks, err := s.fireSchedule(ctx, savepoint, schedule, now)
+			if err == nil {
+				err = savepoint.Commit(ctx)
+			}
+			if err != nil {
+				if rollbackErr := savepoint.Rollback(ctx); rollbackErr != nil {
+					return rollbackErr
+				}
+
+				// Left active, the schedule would stay due and fail again
+				// on every pass.
+				if err := s.store.PauseScheduleTx(ctx, tx, schedule.ID); err != nil {
+					return err
+				}
+
+				s.logger.Error("failing schedule paused", "schedule_id", schedule.ID.String(), "error", err)
+				continue
+			}
+
+			s.logger.Info("schedule fired", "schedule_id", schedule.ID.String(), "jobs_created", ticks)
+		}
+
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("firing schedules failed", "error", err)
+	}
+}
+
+// fireSchedule creates the jobs of the due ticks of schedule and advances
+// it, reporting how many jobs it created.
+func (s *Scheduler) fireSchedule(
+	ctx context.Context,
+	tx pgx.Tx,
+	schedule store.Schedule,
+	now time.Time,
+) (int, error) {
+	cronSchedule, err := cron.Parse(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		return 0, err
+	}
+
+	ticks, nextRunAt := planTicks(cronSchedule, schedule.CatchUpPolicy, schedule.NextRunAt, now)
+
+	for _, tick := range ticks {
+		runAt := tick
+		scheduleID := schedule.ID
+
+		if _, err := store.CreateJobTx(ctx, tx, store.JobSpec{
+			ID:             uuid.New(),
+			Type:           schedule.JobType,
+			Payload:        schedule.Payload,
+			MaxAttempts:    schedule.MaxAttempts,
+			TimeoutSeconds: schedule.TimeoutSeconds,
+			RunAt:          &runAt,
+			ScheduleID:     &scheduleID,
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	var lastRunAt *time.Time
+	if len(ticks) > 0 {
+		lastRunAt = &ticks[len(ticks)-1]
+	}
+
+	if err := s.store.AdvanceSchedule(ctx, tx, schedule.ID, nextRunAt, lastRunAt); err != nil {
+		return 0, err
+	}
+
+	return len(ticks), nil
+}
@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/cron"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+func TestPlanTicks(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	now := at("2026-01-01T12:00:30Z")
+
+	minutesFrom := func(start time.Time, count int) []time.Time {
+		ticks := make([]time.Time, count)
+		for i := range ticks {
+			ticks[i] = start.Add(time.Duration(i) * time.Minute)
+		}
+		return ticks
+	}
+
+	tests := []struct {
+		name          string
+		expression    string
+		policy        string
+		nextRunAt     time.Time
+		wantTicks     []time.Time
+		wantNextRunAt time.Time
+	}{
+		{
+			name:          "not due",
+			expression:    "* * * * *",
+			policy:        store.CatchUpRunAll,
+			nextRunAt:     at("2026-01-01T12:01:00Z"),
+			wantTicks:     nil,
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+		{
+			name:          "run all fires every missed tick",
+			expression:    "* * * * *",
+			policy:        store.CatchUpRunAll,
+			nextRunAt:     at("2026-01-01T11:57:00Z"),
+			wantTicks:     minutesFrom(at("2026-01-01T11:57:00Z"), 4),
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+		{
+			name:          "run all stops at the catch-up limit",
+			expression:    "* * * * *",
+			policy:        store.CatchUpRunAll,
+			nextRunAt:     at("2026-01-01T08:00:00Z"),
+			wantTicks:     minutesFrom(at("2026-01-01T08:00:00Z"), maxCatchUpRuns),
+			wantNextRunAt: at("2026-01-01T08:00:00Z").Add(maxCatchUpRuns * time.Minute),
+		},
+		{
+			name:          "run once fires the latest missed tick",
+			expression:    "* * * * *",
+			policy:        store.CatchUpRunOnce,
+			nextRunAt:     at("2026-01-01T11:57:00Z"),
+			wantTicks:     []time.Time{at("2026-01-01T12:00:00Z")},
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+		{
+			name:          "run once after a year of missed ticks",
+			expression:    "* * * * *",
+			policy:        store.CatchUpRunOnce,
+			nextRunAt:     at("2025-01-01T12:00:00Z"),
+			wantTicks:     []time.Time{at("2026-01-01T12:00:00Z")},
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+		{
+			name:          "run once with only sparse ticks missed",
+			expression:    "0 9 1 * *",
+			policy:        store.CatchUpRunOnce,
+			nextRunAt:     at("2025-06-01T09:00:00Z"),
+			wantTicks:     []time.Time{at("2026-01-01T09:00:00Z")},
+			wantNextRunAt: at("2026-02-01T09:00:00Z"),
+		},
+		{
+			name:          "skip fires a tick within the grace period",
+			expression:    "* * * * *",
+			policy:        store.CatchUpSkip,
+			nextRunAt:     at("2026-01-01T11:57:00Z"),
+			wantTicks:     []time.Time{at("2026-01-01T12:00:00Z")},
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+		{
+			name:          "skip drops a tick past the grace period",
+			expression:    "30 * * * *",
+			policy:        store.CatchUpSkip,
+			nextRunAt:     at("2026-01-01T10:30:00Z"),
+			wantTicks:     nil,
+			wantNextRunAt: at("2026-01-01T12:30:00Z"),
+		},
+		{
+			name:          "skip after a year of missed ticks",
+			expression:    "* * * * *",
+			policy:        store.CatchUpSkip,
+			nextRunAt:     at("2025-01-01T12:00:00Z"),
+			wantTicks:     []time.Time{at("2026-01-01T12:00:00Z")},
+			wantNextRunAt: at("2026-01-01T12:01:00Z"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cronSchedule, err := cron.Parse(test.expression, "UTC")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ticks, nextRunAt := planTicks(cronSchedule, test.policy, test.nextRunAt, now)
+
+			if !slices.EqualFunc(ticks, test.wantTicks, time.Time.Equal) {
+				t.Fatalf("expected ticks %v, got %v", test.wantTicks, ticks)
+			}
+			if !nextRunAt.Equal(test.wantNextRunAt) {
+				t.Fatalf("expected next run at %s, got %s", test.wantNextRunAt, nextRunAt)
+			}
+		})
+	}
+}
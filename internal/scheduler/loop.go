@@ -4,15 +4,29 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/store"
 )
 
 var ErrNoJobsAvailable = errors.New("No jobs available")
 
+// timeoutGracePeriod gives workers a chance to report a timed out job
+// themselves before the scheduler fails it on their behalf.
+const timeoutGracePeriod = 5 * time.Second
+
 func (s *Scheduler) Run(ctx context.Context) {
+	ctx = store.WithActor(ctx, "scheduler:"+s.id.String())
+
 	scheduleTicker := time.NewTicker(500 * time.Millisecond)
 	recoveryTicker := time.NewTicker(2 * time.Second)
+	cronTicker := time.NewTicker(time.Second)
+	purgeTicker := time.NewTicker(time.Minute)
+	agingTicker := time.NewTicker(store.PriorityAgingInterval)
 	defer scheduleTicker.Stop()
 	defer recoveryTicker.Stop()
+	defer cronTicker.Stop()
+	defer purgeTicker.Stop()
+	defer agingTicker.Stop()
 
 	for {
 		select {
@@ -20,6 +34,33 @@ func (s *Scheduler) Run(ctx context.Context) {
 			return
 		case <-scheduleTicker.C:
 			s.tryScheduleOnce(ctx)
+		case <-cronTicker.C:
+			s.fireDueSchedules(ctx)
+		case <-agingTicker.C:
+			if _, err := s.store.AgePendingJobs(ctx); err != nil {
+				s.logger.Error("priority aging failed", "error", err)
+			}
+		case <-purgeTicker.C:
+			purged, err := s.store.PurgeExpiredIdempotencyKeys(ctx, time.Now())
+			if err != nil {
+				s.logger.Error("idempotency key purge failed", "error", err)
+			} else if purged > 0 {
+				s.logger.Info("expired idempotency keys purged", "count", purged)
+			}
+
+			purged, err = s.store.PurgeExpiredJobResults(ctx, time.Now())
+			if err != nil {
+				s.logger.Error("job result purge failed", "error", err)
+			} else if purged > 0 {
+				s.logger.Info("expired job results purged", "count", purged)
+			}
+
+			purged, err = s.store.PurgeExpiredJobLogs(ctx, time.Now())
+			if err != nil {
+				s.logger.Error("job log purge failed", "error", err)
+			} else if purged > 0 {
+				s.logger.Info("expired job logs purged", "count", purged)
+			}
 		case <-recoveryTicker.C:
 			recovered, err := s.store.RecoverExpiredLeases(ctx, time.Now())
 			if err != nil {
@@ -29,40 +70,33 @@ func (s *Scheduler) Run(ctx context.Context) {
 			for _, jobID := range recovered {
 				s.logger.Info("expired lease recovered", "job_id", jobID.String())
 			}
+
+			timedOut, err := s.store.FailTimedOutJobs(ctx, time.Now().Add(-timeoutGracePeriod))
+			if err != nil {
+				s.logger.Error("timeout sweep failed", "error", err)
+			}
+
+			for _, jobID := range timedOut {
+				s.logger.Info("timed out job failed", "job_id", jobID.String())
+			}
 		}
 	}
 }
 
+// tryScheduleOnce leases the next due job. The store only leases jobs of
+// queues that a live worker with free capacity serves.
 func (s *Scheduler) tryScheduleOnce(ctx context.Context) {
-	totalCapacity, err := s.store.GetTotalWorkerCapacity(ctx)
-	if err != nil {
-		return
-	}
-
-	if totalCapacity == 0 {
-		return
-	}
-
-	runningJobs, err := s.store.CountRunningJobs(ctx)
-	if err != nil {
-		return
-	}
-
-	if runningJobs >= totalCapacity {
-		return
-	}
-	
-	jobID, _, err := s.store.AcquireJobLease(
+	lease, err := s.store.AcquireJobLease(
 		ctx,
 		s.id,
-		30*time.Second,
+		store.DefaultLeaseDuration,
 	)
 	if err != nil {
 		// No job acquired is NOT an error condition
 		return
 	}
 
-	s.logger.Info("lease acquired", "job_id", jobID.String())
+	s.logger.Info("lease acquired", "job_id", lease.JobID.String(), "fencing_token", lease.FencingToken)
 
 	// Lease acquired successfully.
 	// At this stage we do nothing else.
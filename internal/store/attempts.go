@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Outcomes of a finished attempt.
+const (
+	AttemptCompleted    = "COMPLETED"
+	AttemptFailed       = "FAILED"
+	AttemptTimedOut     = "TIMED_OUT"
+	AttemptLeaseExpired = "LEASE_EXPIRED"
+	AttemptCancelled    = "CANCELLED"
+)
+
+// JobAttempt is one execution of a job. Attempt numbers start at 1. An
+// attempt that is still running has no FinishedAt and no Outcome.
+type JobAttempt struct {
+	JobID        uuid.UUID
+	Attempt      int
+	WorkerID     *uuid.UUID
+	SchedulerID  uuid.UUID
+	FencingToken int64
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+	Outcome      *string
+	Error        *string
+	Retryable    *bool
+}
+
+// ListJobAttempts returns the attempts of a job, oldest first.
+func (s *Store) ListJobAttempts(
+	ctx context.Context,
+	jobID uuid.UUID,
+) ([]JobAttempt, error) {
+	return s.listAttempts(ctx, `job_id = $1`, jobID)
+}
+
+// listAttempts returns the attempts matching condition, ordered by job and
+// attempt.
+func (s *Store) listAttempts(
+	ctx context.Context,
+	condition string,
+	args ...any,
+) ([]JobAttempt, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT
+			job_id,
+			attempt,
+			worker_id,
+			scheduler_id,
+			fencing_token,
+			started_at,
+			finished_at,
+			outcome,
+			error,
+			retryable
+		FROM job_attempts
+		WHERE `+condition+`
+		ORDER BY job_id, attempt
+		`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []JobAttempt
+
+	for rows.Next() {
+		var attempt JobAttempt
+
+		if err := rows.Scan(
+			&attempt.JobID,
+			&attempt.Attempt,
+			&attempt.WorkerID,
+			&attempt.SchedulerID,
+			&attempt.FencingToken,
+			&attempt.StartedAt,
+			&attempt.FinishedAt,
+			&attempt.Outcome,
+			&attempt.Error,
+			&attempt.Retryable,
+		); err != nil {
+			return nil, err
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// startAttempt opens the attempt of a job that is starting to run, recording
+// who holds its lease, and clears the progress of the previous attempt.
+func startAttempt(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+) error {
+	if _, err := transaction.Exec(
+		ctx,
+		`
+		UPDATE jobs
+		SET progress_percent = NULL,
+			progress_stage = NULL,
+			progress_message = NULL,
+			progress_updated_at = NULL
+		WHERE id = $1
+		`,
+		jobID,
+	); err != nil {
+		return err
+	}
+
+	_, err := transaction.Exec(
+		ctx,
+		`
+		INSERT INTO job_attempts (
+			job_id,
+			attempt,
+			worker_id,
+			scheduler_id,
+			fencing_token
+		)
+		SELECT j.id, j.current_attempt + 1, l.worker_id, l.scheduler_id, l.fencing_token
+		FROM jobs j
+		JOIN job_leases l ON l.job_id = j.id
+		WHERE j.id = $1
+		`,
+		jobID,
+	)
+
+	return err
+}
+
+// finishAttempt closes the job's current attempt. It must run before a retry
+// advances current_attempt. A job without an open attempt is left alone.
+func finishAttempt(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	outcome string,
+	errMessage *string,
+	retryable *bool,
+) error {
+	_, err := transaction.Exec(
+		ctx,
+		`
+		UPDATE job_attempts a
+		SET finished_at = now(),
+			outcome = $2,
+			error = $3,
+			retryable = $4
+		FROM jobs j
+		WHERE j.id = $1
+		  AND a.job_id = j.id
+		  AND a.attempt = j.current_attempt + 1
+		  AND a.finished_at IS NULL
+		`,
+		jobID,
+		outcome,
+		errMessage,
+		retryable,
+	)
+
+	return err
+}
@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestFailedAttemptIsRecorded(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.FailJob(ctx, jobID, fencingToken, "downstream unavailable", true); err != nil {
+		t.Fatal(err)
+	}
+
+	attempts, err := store.ListJobAttempts(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 attempt, got %d", len(attempts))
+	}
+
+	attempt := attempts[0]
+	if attempt.Attempt != 1 || attempt.FencingToken != fencingToken {
+		t.Fatalf("unexpected attempt: %+v", attempt)
+	}
+
+	if attempt.FinishedAt == nil || attempt.Outcome == nil || *attempt.Outcome != AttemptFailed {
+		t.Fatalf("expected a finished %s attempt, got %+v", AttemptFailed, attempt)
+	}
+
+	if attempt.Error == nil || *attempt.Error != "downstream unavailable" || attempt.Retryable == nil || !*attempt.Retryable {
+		t.Fatalf("unexpected attempt error: %+v", attempt)
+	}
+}
+
+// startTimedOutTestJob starts the job of spec and backdates its start past
+// its timeout.
+func startTimedOutTestJob(t *testing.T, store *Store, spec JobSpec) {
+	t.Helper()
+
+	ctx := context.Background()
+	fencingToken := scheduleTestJobSpec(t, store, spec)
+
+	if err := store.MarkJobRunning(ctx, spec.ID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE jobs SET started_at = now() - (timeout_seconds + 1) * interval '1 second' WHERE id = $1`,
+		spec.ID,
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTimedOutJobIsRetried(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	spec := newTestJobSpec(uuid.New())
+	startTimedOutTestJob(t, store, spec)
+
+	onTimeJobID := uuid.New()
+	onTimeFencingToken := scheduleTestJob(t, store, onTimeJobID)
+	if err := store.MarkJobRunning(ctx, onTimeJobID, onTimeFencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	timedOut, err := store.FailTimedOutJobs(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(timedOut, spec.ID) {
+		t.Fatalf("expected job %s to time out, got %v", spec.ID, timedOut)
+	}
+	if slices.Contains(timedOut, onTimeJobID) {
+		t.Fatalf("expected job %s within its timeout to keep running", onTimeJobID)
+	}
+
+	assertJobState(t, store, spec.ID, JobPending)
+	assertJobState(t, store, onTimeJobID, JobRunning)
+
+	attempts, err := store.ListJobAttempts(ctx, spec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Outcome == nil || *attempts[0].Outcome != AttemptTimedOut {
+		t.Fatalf("expected one %s attempt, got %+v", AttemptTimedOut, attempts)
+	}
+	if attempts[0].Retryable == nil || !*attempts[0].Retryable {
+		t.Fatalf("expected a timeout to be retryable, got %+v", attempts[0])
+	}
+}
+
+func TestTimedOutJobFailsOnLastAttempt(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	spec := newTestJobSpec(uuid.New())
+	spec.MaxAttempts = 1
+	startTimedOutTestJob(t, store, spec)
+
+	if _, err := store.FailTimedOutJobs(ctx, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	assertJobState(t, store, spec.ID, JobFailed)
+
+	attempts, err := store.ListJobAttempts(ctx, spec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Outcome == nil || *attempts[0].Outcome != AttemptTimedOut {
+		t.Fatalf("expected one %s attempt, got %+v", AttemptTimedOut, attempts)
+	}
+}
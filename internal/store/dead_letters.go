@@ -0,0 +1,408 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeadLetter is a job that failed for good, either because it ran out of
+// attempts or because its failure was not retryable. Attempts holds every
+// attempt of the job with its error, oldest first.
+type DeadLetter struct {
+	ID            uuid.UUID
+	Job           Job
+	Attempts      []JobAttempt
+	CreatedAt     time.Time
+	ReplayedJobID *uuid.UUID
+	ReplayedAt    *time.Time
+}
+
+// DeadLetterFilter selects dead letters. IDs, JobType and Queue narrow the
+// selection when set, and Replayed selects by whether a dead letter was
+// already replayed.
+type DeadLetterFilter struct {
+	IDs      []uuid.UUID
+	JobType  *string
+	Queue    *string
+	Replayed *bool
+	Limit    int
+}
+
+// ReplayedDeadLetter maps a replayed dead letter to the job created for it.
+type ReplayedDeadLetter struct {
+	DeadLetterID uuid.UUID
+	JobID        uuid.UUID
+}
+
+const deadLetterColumns = `
+	d.id,
+	d.created_at,
+	d.replayed_job_id,
+	d.replayed_at,
+` + jobColumns
+
+func scanDeadLetter(row rowScanner) (DeadLetter, error) {
+	var deadLetter DeadLetter
+
+	job, err := scanJob(prefixScanner{
+		row: row,
+		prefix: []any{
+			&deadLetter.ID,
+			&deadLetter.CreatedAt,
+			&deadLetter.ReplayedJobID,
+			&deadLetter.ReplayedAt,
+		},
+	})
+	deadLetter.Job = job
+
+	return deadLetter, err
+}
+
+// prefixScanner scans the leading columns of a row into prefix and the rest
+// into the destinations passed to Scan, so scanJob can read rows that select
+// more than jobColumns.
+type prefixScanner struct {
+	row    rowScanner
+	prefix []any
+}
+
+func (p prefixScanner) Scan(dest ...any) error {
+	return p.row.Scan(append(p.prefix, dest...)...)
+}
+
+// deadLetterWhere builds the WHERE clause of a dead letter query. Numbered
+// parameters start after args.
+func deadLetterWhere(filter DeadLetterFilter, args []any) (string, []any) {
+	var conditions []string
+
+	if len(filter.IDs) > 0 {
+		args = append(args, filter.IDs)
+		conditions = append(conditions, fmt.Sprintf("d.id = ANY($%d)", len(args)))
+	}
+
+	if filter.JobType != nil {
+		args = append(args, *filter.JobType)
+		conditions = append(conditions, fmt.Sprintf("j.type = $%d", len(args)))
+	}
+
+	if filter.Queue != nil {
+		args = append(args, *filter.Queue)
+		conditions = append(conditions, fmt.Sprintf("j.queue = $%d", len(args)))
+	}
+
+	if filter.Replayed != nil {
+		if *filter.Replayed {
+			conditions = append(conditions, "d.replayed_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "d.replayed_at IS NULL")
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (s *Store) ListDeadLetters(
+	ctx context.Context,
+	filter DeadLetterFilter,
+) ([]DeadLetter, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	where, args := deadLetterWhere(filter, nil)
+	args = append(args, limit)
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters d
+		JOIN jobs j ON j.id = d.job_id
+		LEFT JOIN job_leases l ON l.job_id = j.id
+		`+where+`
+		ORDER BY d.created_at DESC
+		LIMIT `+fmt.Sprintf("$%d", len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deadLetters []DeadLetter
+
+	for rows.Next() {
+		deadLetter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deadLetters = append(deadLetters, deadLetter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachDeadLetterAttempts(ctx, deadLetters); err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+func (s *Store) GetDeadLetterByID(
+	ctx context.Context,
+	deadLetterID uuid.UUID,
+) (*DeadLetter, error) {
+	row := s.connectionPool.QueryRow(
+		ctx,
+		`
+		SELECT `+deadLetterColumns+`
+		FROM dead_letters d
+		JOIN jobs j ON j.id = d.job_id
+		LEFT JOIN job_leases l ON l.job_id = j.id
+		WHERE d.id = $1
+		`,
+		deadLetterID,
+	)
+
+	deadLetter, err := scanDeadLetter(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	deadLetters := []DeadLetter{deadLetter}
+	if err := s.attachDeadLetterAttempts(ctx, deadLetters); err != nil {
+		return nil, err
+	}
+
+	return &deadLetters[0], nil
+}
+
+// attachDeadLetterAttempts loads the attempts of the failed jobs of
+// deadLetters with a single query.
+func (s *Store) attachDeadLetterAttempts(
+	ctx context.Context,
+	deadLetters []DeadLetter,
+) error {
+	if len(deadLetters) == 0 {
+		return nil
+	}
+
+	jobIDs := make([]uuid.UUID, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		jobIDs = append(jobIDs, deadLetter.Job.ID)
+	}
+
+	attempts, err := s.listAttempts(ctx, `job_id = ANY($1)`, jobIDs)
+	if err != nil {
+		return err
+	}
+
+	attemptsByJob := make(map[uuid.UUID][]JobAttempt, len(deadLetters))
+	for _, attempt := range attempts {
+		attemptsByJob[attempt.JobID] = append(attemptsByJob[attempt.JobID], attempt)
+	}
+
+	for i := range deadLetters {
+		deadLetters[i].Attempts = attemptsByJob[deadLetters[i].Job.ID]
+	}
+
+	return nil
+}
+
+// MaxDeadLetterReplayBatch is the most dead letters a single replay
+// considers, and the batch size when the filter sets no limit.
+const MaxDeadLetterReplayBatch = 1000
+
+// ReplayDeadLetters creates a new PENDING job for every selected dead
+// letter that was not replayed yet, oldest first and at most filter.Limit
+// of them. The failed job is left untouched; the new job records it in
+// ReplayOfJobID. Dead letters whose unique key is held by an unfinished job
+// are skipped. It also reports how many selected dead letters remain to be
+// replayed, so that callers can replay in batches until none are left.
+func (s *Store) ReplayDeadLetters(
+	ctx context.Context,
+	filter DeadLetterFilter,
+) ([]ReplayedDeadLetter, int64, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > MaxDeadLetterReplayBatch {
+		limit = MaxDeadLetterReplayBatch
+	}
+
+	notReplayed := false
+	filter.Replayed = &notReplayed
+
+	var (
+		replayed  []ReplayedDeadLetter
+		remaining int64
+	)
+
+	err := s.WithTransaction(ctx, func(tx pgx.Tx) error {
+		where, args := deadLetterWhere(filter, nil)
+		args = append(args, limit)
+
+		rows, err := tx.Query(
+			ctx,
+			`
+			SELECT `+deadLetterColumns+`
+			FROM dead_letters d
+			JOIN jobs j ON j.id = d.job_id
+			LEFT JOIN job_leases l ON l.job_id = j.id
+			`+where+`
+			ORDER BY d.created_at
+			FOR UPDATE OF d SKIP LOCKED
+			LIMIT `+fmt.Sprintf("$%d", len(args)),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+
+		var deadLetters []DeadLetter
+
+		for rows.Next() {
+			deadLetter, err := scanDeadLetter(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			deadLetters = append(deadLetters, deadLetter)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, deadLetter := range deadLetters {
+			failed := deadLetter.Job
+			spec := JobSpec{
+				ID:               uuid.New(),
+				Type:             failed.Type,
+				Payload:          failed.Payload,
+				MaxAttempts:      failed.MaxAttempts,
+				TimeoutSeconds:   failed.TimeoutSeconds,
+				RetryPolicy:      failed.RetryPolicy,
+				Priority:         failed.Priority,
+				Queue:            failed.Queue,
+				ConcurrencyLimit: failed.ConcurrencyLimit,
+				ReplayOfJobID:    &failed.ID,
+			}
+			if failed.UniqueKey != nil {
+				spec.UniqueKey = *failed.UniqueKey
+			}
+			if failed.ConcurrencyKey != nil {
+				spec.ConcurrencyKey = *failed.ConcurrencyKey
+			}
+
+			jobID, err := CreateJobTx(ctx, tx, spec)
+			if err == ErrUniqueJobExists {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if _, err := tx.Exec(
+				ctx,
+				`
+				UPDATE dead_letters
+				SET replayed_job_id = $2,
+					replayed_at = now()
+				WHERE id = $1
+				`,
+				deadLetter.ID,
+				jobID,
+			); err != nil {
+				return err
+			}
+
+			replayed = append(replayed, ReplayedDeadLetter{
+				DeadLetterID: deadLetter.ID,
+				JobID:        jobID,
+			})
+		}
+
+		where, args = deadLetterWhere(filter, nil)
+
+		return tx.QueryRow(
+			ctx,
+			`
+			SELECT COUNT(*)
+			FROM dead_letters d
+			JOIN jobs j ON j.id = d.job_id
+			`+where,
+			args...,
+		).Scan(&remaining)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return replayed, remaining, nil
+}
+
+// PurgeDeadLetters removes the selected dead letters and reports how many
+// were removed. The failed jobs themselves are kept.
+func (s *Store) PurgeDeadLetters(
+	ctx context.Context,
+	filter DeadLetterFilter,
+) (int64, error) {
+	where, args := deadLetterWhere(filter, nil)
+
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`
+		DELETE FROM dead_letters
+		WHERE id IN (
+			SELECT d.id
+			FROM dead_letters d
+			JOIN jobs j ON j.id = d.job_id
+			`+where+`
+		)
+		`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// deadLetterJob files a job that just failed for good as a dead letter.
+func deadLetterJob(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+) error {
+	_, err := transaction.Exec(
+		ctx,
+		`
+		INSERT INTO dead_letters (id, job_id)
+		VALUES ($1, $2)
+		ON CONFLICT (job_id) DO NOTHING
+		`,
+		uuid.New(),
+		jobID,
+	)
+
+	return err
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNonRetryableFailureIsDeadLetteredAndReplayed(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := store.FailJob(ctx, jobID, fencingToken, "malformed payload", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != JobFailed {
+		t.Fatalf("expected FailJob to report %s, got %s", JobFailed, state)
+	}
+
+	jobType := newTestJobSpec(jobID).Type
+	notReplayed := false
+
+	deadLetters, err := store.ListDeadLetters(ctx, DeadLetterFilter{
+		JobType:  &jobType,
+		Replayed: &notReplayed,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var deadLetter *DeadLetter
+	for i := range deadLetters {
+		if deadLetters[i].Job.ID == jobID {
+			deadLetter = &deadLetters[i]
+		}
+	}
+	if deadLetter == nil {
+		t.Fatalf("expected job %s to be dead-lettered", jobID)
+	}
+
+	if len(deadLetter.Attempts) != 1 ||
+		deadLetter.Attempts[0].Error == nil ||
+		*deadLetter.Attempts[0].Error != "malformed payload" {
+		t.Fatalf("unexpected attempts: %+v", deadLetter.Attempts)
+	}
+
+	replayed, remaining, err := store.ReplayDeadLetters(ctx, DeadLetterFilter{IDs: []uuid.UUID{deadLetter.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 1 || remaining != 0 {
+		t.Fatalf("expected one replayed dead letter and none remaining, got %d and %d", len(replayed), remaining)
+	}
+
+	replayJob, err := store.GetJobByID(ctx, replayed[0].JobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayJob.State != JobPending || replayJob.ReplayOfJobID == nil || *replayJob.ReplayOfJobID != jobID {
+		t.Fatalf("unexpected replay job: %+v", replayJob)
+	}
+
+	assertJobState(t, store, jobID, JobFailed)
+
+	replayed, _, err = store.ReplayDeadLetters(ctx, DeadLetterFilter{IDs: []uuid.UUID{deadLetter.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replayed) != 0 {
+		t.Fatalf("expected an already replayed dead letter to be skipped, got %d", len(replayed))
+	}
+}
+
+func TestReplayDeadLettersInBatches(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	var deadLetterIDs []uuid.UUID
+
+	for range 2 {
+		jobID := uuid.New()
+		fencingToken := scheduleTestJob(t, store, jobID)
+
+		if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.FailJob(ctx, jobID, fencingToken, "malformed payload", false); err != nil {
+			t.Fatal(err)
+		}
+
+		var deadLetterID uuid.UUID
+		if err := store.connectionPool.QueryRow(
+			ctx,
+			`SELECT id FROM dead_letters WHERE job_id = $1`,
+			jobID,
+		).Scan(&deadLetterID); err != nil {
+			t.Fatal(err)
+		}
+
+		deadLetterIDs = append(deadLetterIDs, deadLetterID)
+	}
+
+	filter := DeadLetterFilter{IDs: deadLetterIDs, Limit: 1}
+
+	for _, expectedRemaining := range []int64{1, 0} {
+		replayed, remaining, err := store.ReplayDeadLetters(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(replayed) != 1 || remaining != expectedRemaining {
+			t.Fatalf("expected one replayed and %d remaining, got %d and %d", expectedRemaining, len(replayed), remaining)
+		}
+	}
+}
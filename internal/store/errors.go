@@ -2,4 +2,9 @@ package store
 
 import "errors"
 
-var ErrInvalidStateTransition = errors.New("invalid job state transition")
+var (
+	ErrInvalidStateTransition = errors.New("invalid job state transition")
+	ErrLeaseNotHeld           = errors.New("job lease not held")
+	ErrStaleFencingToken      = errors.New("stale lease fencing token")
+	ErrUniqueJobExists        = errors.New("an unfinished job with this unique key exists")
+)
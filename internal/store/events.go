@@ -0,0 +1,401 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidEventCursor = errors.New("invalid event cursor")
+
+// SystemActor is recorded for transitions made without an actor in the
+// context.
+const SystemActor = "system"
+
+type actorKeyType struct{}
+
+var actorKey = actorKeyType{}
+
+// WithActor attributes the job state transitions made with the returned
+// context to actor, e.g. "request:<id>", "scheduler:<id>" or "worker:<id>".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func actorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+
+	return SystemActor
+}
+
+// JobEvent is one entry of a job's audit log. FromState is nil for the event
+// recording the job's creation.
+type JobEvent struct {
+	ID        int64
+	JobID     uuid.UUID
+	FromState *string
+	ToState   string
+	Actor     string
+	Reason    *string
+	CreatedAt time.Time
+}
+
+// ListJobEvents returns the audit log of a job, oldest first.
+func (s *Store) ListJobEvents(
+	ctx context.Context,
+	jobID uuid.UUID,
+) ([]JobEvent, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT id, job_id, from_state, to_state, actor, reason, created_at
+		FROM job_events
+		WHERE job_id = $1
+		ORDER BY id
+		`,
+		jobID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []JobEvent
+
+	for rows.Next() {
+		var event JobEvent
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.JobID,
+			&event.FromState,
+			&event.ToState,
+			&event.Actor,
+			&event.Reason,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// recordJobEvent appends to the job's audit log, attributing the event to
+// the actor carried by ctx, queues a delivery for every matching webhook and
+// notifies JobEventsChannel listeners. Deliveries and the notification only
+// take effect if the transaction commits. An empty from records the job's
+// creation.
+func recordJobEvent(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	to string,
+	from string,
+	reason string,
+) error {
+	// The reason is left out of the notification to stay well within the
+	// NOTIFY payload limit.
+	_, err := transaction.Exec(
+		ctx,
+		`
+		WITH event AS (
+			INSERT INTO job_events (job_id, from_state, to_state, actor, reason)
+			VALUES ($1, NULLIF($2, ''), $3, $4, NULLIF($5, ''))
+			RETURNING id, job_id, from_state, to_state, actor, reason, created_at
+		),
+		deliveries AS (
+			INSERT INTO webhook_deliveries (id, webhook_id, event_id, job_id, payload)
+			SELECT
+				gen_random_uuid(),
+				w.id,
+				e.id,
+				e.job_id,
+				json_build_object(
+					'event_id', e.id,
+					'job_id', e.job_id,
+					'job_type', j.type,
+					'queue', j.queue,
+					'from_state', e.from_state,
+					'to_state', e.to_state,
+					'actor', e.actor,
+					'reason', e.reason,
+					'created_at', e.created_at
+				)
+			FROM event e
+			JOIN jobs j ON j.id = e.job_id
+			JOIN webhooks w
+			  ON (w.job_type IS NULL OR w.job_type = j.type)
+			 AND (w.queue IS NULL OR w.queue = j.queue)
+			 AND (w.states IS NULL OR e.to_state = ANY(w.states))
+		)
+		SELECT pg_notify('`+JobEventsChannel+`', json_build_object(
+			'event_id', e.id,
+			'job_id', e.job_id,
+			'job_type', j.type,
+			'queue', j.queue,
+			'from_state', e.from_state,
+			'to_state', e.to_state,
+			'actor', e.actor,
+			'created_at', e.created_at
+		)::text)
+		FROM event e
+		JOIN jobs j ON j.id = e.job_id
+		`,
+		jobID,
+		from,
+		to,
+		actorFromContext(ctx),
+		reason,
+	)
+
+	return err
+}
+
+// EventCursor is a position in the change feed. The zero cursor is the start
+// of the feed.
+type EventCursor struct {
+	TxID int64
+	ID   int64
+}
+
+// ParseEventCursor parses a cursor produced by EventCursor.String. The empty
+// string is the start of the feed.
+func ParseEventCursor(raw string) (EventCursor, error) {
+	if raw == "" {
+		return EventCursor{}, nil
+	}
+
+	var cursor EventCursor
+
+	if _, err := fmt.Sscanf(raw, "%d-%d", &cursor.TxID, &cursor.ID); err != nil ||
+		cursor.String() != raw {
+		return EventCursor{}, ErrInvalidEventCursor
+	}
+
+	return cursor, nil
+}
+
+func (c EventCursor) String() string {
+	return fmt.Sprintf("%d-%d", c.TxID, c.ID)
+}
+
+// FeedEvent is a job event as seen by the change feed.
+type FeedEvent struct {
+	JobEvent
+	JobType string
+	Queue   string
+	Cursor  EventCursor
+}
+
+// EventFeedFilter selects the events following After, optionally narrowed to
+// one job type or queue.
+type EventFeedFilter struct {
+	After   EventCursor
+	JobType *string
+	Queue   *string
+	Limit   int
+}
+
+// ListEventsAfter returns the events following filter.After in feed order,
+// and the cursor to resume from.
+//
+// Events are ordered by the id of the transaction that wrote them, and only
+// events of transactions older than the oldest one still running are
+// returned. A transaction that commits late therefore cannot slip in behind
+// a cursor a client already holds, which keeps the feed gap-free. The price
+// is that a long-running transaction holds the feed back until it ends.
+func (s *Store) ListEventsAfter(
+	ctx context.Context,
+	filter EventFeedFilter,
+) ([]FeedEvent, EventCursor, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	args := []any{filter.After.TxID, filter.After.ID}
+	conditions := []string{
+		"(e.txid, e.id) > ($1, $2)",
+		"e.txid < txid_snapshot_xmin(txid_current_snapshot())",
+	}
+
+	if filter.JobType != nil {
+		args = append(args, *filter.JobType)
+		conditions = append(conditions, fmt.Sprintf("j.type = $%d", len(args)))
+	}
+
+	if filter.Queue != nil {
+		args = append(args, *filter.Queue)
+		conditions = append(conditions, fmt.Sprintf("j.queue = $%d", len(args)))
+	}
+
+	args = append(args, limit)
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT
+			e.id,
+			e.job_id,
+			e.from_state,
+			e.to_state,
+			e.actor,
+			e.reason,
+			e.created_at,
+			e.txid,
+			j.type,
+			j.queue
+		FROM job_events e
+		JOIN jobs j ON j.id = e.job_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY e.txid, e.id
+		LIMIT `+fmt.Sprintf("$%d", len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, filter.After, err
+	}
+	defer rows.Close()
+
+	var events []FeedEvent
+
+	for rows.Next() {
+		var event FeedEvent
+
+		if err := rows.Scan(
+			&event.ID,
+			&event.JobID,
+			&event.FromState,
+			&event.ToState,
+			&event.Actor,
+			&event.Reason,
+			&event.CreatedAt,
+			&event.Cursor.TxID,
+			&event.JobType,
+			&event.Queue,
+		); err != nil {
+			return nil, filter.After, err
+		}
+		event.Cursor.ID = event.ID
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, filter.After, err
+	}
+
+	next := filter.After
+	if len(events) > 0 {
+		next = events[len(events)-1].Cursor
+	}
+
+	return events, next, nil
+}
+
+// JobEventsChannel is the Postgres notification channel every job event is
+// announced on.
+const JobEventsChannel = "job_events"
+
+// JobEventNotification announces a job event to listeners.
+type JobEventNotification struct {
+	EventID   int64     `json:"event_id"`
+	JobID     uuid.UUID `json:"job_id"`
+	JobType   string    `json:"job_type"`
+	Queue     string    `json:"queue"`
+	FromState *string   `json:"from_state"`
+	ToState   string    `json:"to_state"`
+	Actor     string    `json:"actor"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListenJobEvents calls handle for every job event committed while it runs.
+// It holds a dedicated connection until ctx is done or the connection
+// fails, and returns the error that stopped it. listening is called once
+// notifications are being received. Events committed while no listener is
+// connected are not replayed; use ListEventsAfter to catch up.
+func (s *Store) ListenJobEvents(
+	ctx context.Context,
+	listening func(),
+	handle func(JobEventNotification),
+) error {
+	poolConnection, err := s.connectionPool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection keeps listening until closed, so it is never handed
+	// back to the pool.
+	connection := poolConnection.Hijack()
+	defer connection.Close(context.Background())
+
+	if _, err := connection.Exec(ctx, "LISTEN "+JobEventsChannel); err != nil {
+		return err
+	}
+
+	listening()
+
+	for {
+		notification, err := connection.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event JobEventNotification
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			continue
+		}
+
+		handle(event)
+	}
+}
+
+// GetJobForWatch returns a job together with the ID of its latest event,
+// read from the same snapshot, so that a watcher can tell which
+// notifications the job already reflects. It returns nil when the job does
+// not exist.
+func (s *Store) GetJobForWatch(
+	ctx context.Context,
+	jobID uuid.UUID,
+) (*Job, int64, error) {
+	var latestEventID int64
+
+	row := s.connectionPool.QueryRow(
+		ctx,
+		`
+		SELECT
+			(SELECT COALESCE(MAX(id), 0) FROM job_events WHERE job_id = j.id),
+		`+jobColumns+`
+		FROM jobs j
+		LEFT JOIN job_leases l ON l.job_id = j.id
+		WHERE j.id = $1
+		`,
+		jobID,
+	)
+
+	job, err := scanJob(prefixScanner{row: row, prefix: []any{&latestEventID}})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, 0, nil
+		}
+
+		return nil, 0, err
+	}
+
+	return &job, latestEventID, nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCancellationRecordsActor(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CancelJob(WithActor(ctx, "request:audit"), jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.ListJobEvents(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if events[0].FromState != nil || events[0].ToState != JobPending || events[0].Actor != SystemActor {
+		t.Fatalf("unexpected creation event: %+v", events[0])
+	}
+
+	cancelled := events[1]
+	if cancelled.FromState == nil || *cancelled.FromState != JobPending || cancelled.ToState != JobCancelled {
+		t.Fatalf("unexpected cancellation event: %+v", cancelled)
+	}
+
+	if cancelled.Actor != "request:audit" {
+		t.Fatalf("expected actor request:audit, got %s", cancelled.Actor)
+	}
+}
+
+func TestEventCursorRoundTrip(t *testing.T) {
+	cursor := EventCursor{TxID: 1042, ID: 7}
+
+	parsed, err := ParseEventCursor(cursor.String())
+	if err != nil || parsed != cursor {
+		t.Fatalf("expected %v, got %v, %v", cursor, parsed, err)
+	}
+
+	for _, raw := range []string{"abc", "1-", "1-2-3", "01-2"} {
+		if _, err := ParseEventCursor(raw); err != ErrInvalidEventCursor {
+			t.Fatalf("expected ErrInvalidEventCursor for %q, got %v", raw, err)
+		}
+	}
+}
+
+func TestEventFeedResumesAfterCursor(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobType := "feed-" + uuid.NewString()
+	spec := newTestJobSpec(uuid.New())
+	spec.Type = jobType
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	events, next, err := store.ListEventsAfter(ctx, EventFeedFilter{JobType: &jobType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].JobID != spec.ID || events[0].ToState != JobPending {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	if err := store.CancelJob(ctx, spec.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	events, _, err = store.ListEventsAfter(ctx, EventFeedFilter{After: next, JobType: &jobType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ToState != JobCancelled {
+		t.Fatalf("expected only the cancellation after the cursor, got %+v", events)
+	}
+}
+
+func TestJobEventsAreNotified(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	notified := make(chan JobEventNotification, 16)
+	ready := make(chan struct{})
+	listening := make(chan error, 1)
+
+	go func() {
+		listening <- store.ListenJobEvents(ctx, func() { close(ready) }, func(event JobEventNotification) {
+			if event.JobID == jobID {
+				notified <- event
+			}
+		})
+	}()
+
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The listener must be connected before the transition it waits for.
+	select {
+	case <-ready:
+	case err := <-listening:
+		t.Fatalf("listener stopped: %v", err)
+	case <-ctx.Done():
+		t.Fatal("listener did not connect")
+	}
+
+	if err := store.CancelJob(ctx, jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		select {
+		case event := <-notified:
+			if event.ToState == JobCancelled {
+				if event.FromState == nil || *event.FromState != JobPending {
+					t.Fatalf("unexpected notification: %+v", event)
+				}
+				return
+			}
+		case err := <-listening:
+			t.Fatalf("listener stopped: %v", err)
+		case <-ctx.Done():
+			t.Fatal("no notification for the cancellation")
+		}
+	}
+}
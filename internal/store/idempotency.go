@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrIdempotencyKeyConflict = errors.New("idempotency key reused with a different request")
+
+var errIdempotencyKeyTaken = errors.New("idempotency key taken")
+
+// IdempotencyKey identifies a client request. RequestHash fingerprints the
+// request body so that a key reused for a different request is detected.
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
+	ExpiresAt   time.Time
+}
+
+// JobCreation is the outcome of a job creation request: the job it resolved
+// to, the job's state at the time, and whether that is an existing unique
+// job rather than a new one. Replayed is set when the outcome was recorded
+// by an earlier request with the same idempotency key.
+type JobCreation struct {
+	JobID    uuid.UUID
+	State    string
+	Existing bool
+	Replayed bool
+}
+
+// CreateJobIdempotent creates the job unless the key was already used for
+// an identical request, in which case the outcome of that request is
+// returned as it was first reported, with Replayed set. Reusing an unexpired
+// key for a different request fails with ErrIdempotencyKeyConflict.
+//
+// The job and the key are written in one transaction, and concurrent
+// requests with the same key serialize on the key's primary key, so at most
+// one job is ever created per key.
+func (s *Store) CreateJobIdempotent(
+	ctx context.Context,
+	spec JobSpec,
+	key IdempotencyKey,
+) (JobCreation, error) {
+	var creation JobCreation
+
+	err := s.WithTransaction(ctx, func(tx pgx.Tx) error {
+		createdJobID, err := CreateJobTx(ctx, tx, spec)
+		if err != nil {
+			return err
+		}
+
+		creation.JobID = createdJobID
+		creation.Existing = createdJobID != spec.ID
+
+		if err := tx.QueryRow(
+			ctx,
+			`SELECT state FROM jobs WHERE id = $1`,
+			createdJobID,
+		).Scan(&creation.State); err != nil {
+			return err
+		}
+
+		// An expired key is taken over as if it never existed.
+		err = tx.QueryRow(
+			ctx,
+			`
+			INSERT INTO idempotency_keys (key, request_hash, job_id, job_state, existing_job, expires_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (key) DO UPDATE
+			SET request_hash = EXCLUDED.request_hash,
+				job_id = EXCLUDED.job_id,
+				job_state = EXCLUDED.job_state,
+				existing_job = EXCLUDED.existing_job,
+				created_at = now(),
+				expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()
+			RETURNING job_id
+			`,
+			key.Key,
+			key.RequestHash,
+			createdJobID,
+			creation.State,
+			creation.Existing,
+			key.ExpiresAt,
+		).Scan(&creation.JobID)
+		if err == pgx.ErrNoRows {
+			return errIdempotencyKeyTaken
+		}
+
+		return err
+	})
+	if err == nil {
+		return creation, nil
+	}
+	if !errors.Is(err, errIdempotencyKeyTaken) {
+		return JobCreation{}, err
+	}
+
+	var requestHash string
+
+	creation = JobCreation{Replayed: true}
+
+	if err := s.connectionPool.QueryRow(
+		ctx,
+		`
+		SELECT request_hash, job_id, job_state, existing_job
+		FROM idempotency_keys
+		WHERE key = $1
+		`,
+		key.Key,
+	).Scan(&requestHash, &creation.JobID, &creation.State, &creation.Existing); err != nil {
+		return JobCreation{}, err
+	}
+
+	if requestHash != key.RequestHash {
+		return JobCreation{}, ErrIdempotencyKeyConflict
+	}
+
+	return creation, nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes keys whose retention window ended
+// before now and reports how many were removed.
+func (s *Store) PurgeExpiredIdempotencyKeys(
+	ctx context.Context,
+	now time.Time,
+) (int64, error) {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`DELETE FROM idempotency_keys WHERE expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
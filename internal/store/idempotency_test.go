@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIdempotentJobCreation(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	key := IdempotencyKey{
+		Key:         uuid.NewString(),
+		RequestHash: "first",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	firstJobID := uuid.New()
+	creation, err := store.CreateJobIdempotent(ctx, newTestJobSpec(firstJobID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creation.Replayed || creation.Existing || creation.JobID != firstJobID || creation.State != JobPending {
+		t.Fatalf("expected new job %s, got %+v", firstJobID, creation)
+	}
+
+	retryJobID := uuid.New()
+	creation, err = store.CreateJobIdempotent(ctx, newTestJobSpec(retryJobID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !creation.Replayed || creation.Existing || creation.JobID != firstJobID || creation.State != JobPending {
+		t.Fatalf("expected replay of %s, got %+v", firstJobID, creation)
+	}
+
+	if job, err := store.GetJobByID(ctx, retryJobID); err != nil || job != nil {
+		t.Fatalf("expected replay not to create a job, got %v, %v", job, err)
+	}
+
+	key.RequestHash = "second"
+	if _, err := store.CreateJobIdempotent(ctx, newTestJobSpec(uuid.New()), key); !errors.Is(err, ErrIdempotencyKeyConflict) {
+		t.Fatalf("expected ErrIdempotencyKeyConflict, got %v", err)
+	}
+}
+
+func TestIdempotentReplayOfExistingUniqueJob(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	uniqueKey := "report-" + uuid.NewString()
+
+	heldSpec := newTestJobSpec(uuid.New())
+	heldSpec.UniqueKey = uniqueKey
+	if _, err := store.CreateJob(ctx, heldSpec); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.CancelJob(context.Background(), heldSpec.ID)
+	})
+
+	key := IdempotencyKey{
+		Key:         uuid.NewString(),
+		RequestHash: "unique",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	spec := newTestJobSpec(uuid.New())
+	spec.UniqueKey = uniqueKey
+	spec.OnConflict = UniqueConflictReturnExisting
+
+	first, err := store.CreateJobIdempotent(ctx, spec, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Existing || first.JobID != heldSpec.ID || first.State != JobPending {
+		t.Fatalf("expected existing job %s, got %+v", heldSpec.ID, first)
+	}
+
+	// The replay reports the original outcome even after the job moved on.
+	if err := store.CancelJob(ctx, heldSpec.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	spec.ID = uuid.New()
+	replay, err := store.CreateJobIdempotent(ctx, spec, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replay.Replayed || !replay.Existing || replay.JobID != heldSpec.ID || replay.State != JobPending {
+		t.Fatalf("expected the original outcome to be replayed, got %+v", replay)
+	}
+}
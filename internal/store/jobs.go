@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,45 +15,367 @@ import (
 // Any direct UPDATE of jobs.state outside this gate is a correctness bug.
 
 type Job struct {
-	ID             uuid.UUID
-	State          string
-	Payload        []byte
-	MaxAttempts    int
-	CurrentAttempt int
-	TimeoutSeconds int
-	LastError      *string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	CancelledAt    *time.Time
+	ID               uuid.UUID
+	Type             string
+	State            string
+	Payload          []byte
+	MaxAttempts      int
+	CurrentAttempt   int
+	TimeoutSeconds   int
+	LastError        *string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StartedAt        *time.Time
+	CancelledAt      *time.Time
+	WorkerID         *uuid.UUID
+	RetryPolicy      RetryPolicy
+	RunAt            *time.Time
+	NextRunAt        time.Time
+	ScheduleID       *uuid.UUID
+	Priority         int
+	Queue            string
+	WorkflowID       *uuid.UUID
+	UniqueKey        *string
+	ConcurrencyKey   *string
+	ConcurrencyLimit int
+	ReplayOfJobID    *uuid.UUID
+	Progress         *JobProgress
 }
 
+// Conflict policies for JobSpec.OnConflict.
+const (
+	UniqueConflictReject         = "reject"
+	UniqueConflictReturnExisting = "return_existing"
+	UniqueConflictReplace        = "replace"
+)
+
+// JobSpec describes a job to be created. A zero RetryPolicy means
+// DefaultRetryPolicy, and a nil RunAt makes the job eligible immediately.
+// Jobs with a higher Priority are leased first. An empty Queue means
+// DefaultQueue. At most one unfinished job may hold a UniqueKey; OnConflict
+// decides what happens when another one does. At most ConcurrencyLimit jobs
+// sharing a ConcurrencyKey are SCHEDULED or RUNNING at once; a zero limit
+// means 1. ReplayOfJobID links a job replayed from a dead letter to the job
+// that failed.
+type JobSpec struct {
+	ID               uuid.UUID
+	Type             string
+	Payload          []byte
+	MaxAttempts      int
+	TimeoutSeconds   int
+	RetryPolicy      RetryPolicy
+	RunAt            *time.Time
+	ScheduleID       *uuid.UUID
+	Priority         int
+	Queue            string
+	UniqueKey        string
+	OnConflict       string
+	ConcurrencyKey   string
+	ConcurrencyLimit int
+	ReplayOfJobID    *uuid.UUID
+}
+
+// jobColumns selects a Job from jobs j LEFT JOIN job_leases l; keep it in
+// sync with scanJob.
+const jobColumns = `
+	j.id,
+	j.type,
+	j.state,
+	j.payload,
+	j.max_attempts,
+	j.current_attempt,
+	j.timeout_seconds,
+	j.last_error,
+	j.created_at,
+	j.updated_at,
+	j.started_at,
+	j.cancelled_at,
+	l.worker_id,
+	j.retry_initial_delay_seconds,
+	j.retry_backoff_multiplier,
+	j.retry_max_delay_seconds,
+	j.retry_jitter,
+	j.run_at,
+	j.next_run_at,
+	j.schedule_id,
+	j.priority,
+	j.queue,
+	j.workflow_id,
+	j.unique_key,
+	j.concurrency_key,
+	j.concurrency_limit,
+	j.replay_of_job_id,
+	j.progress_percent,
+	j.progress_stage,
+	j.progress_message,
+	j.progress_updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row rowScanner) (Job, error) {
+	var (
+		job                 Job
+		initialDelaySeconds int
+		maxDelaySeconds     int
+		progressPercent     *int
+		progressStage       *string
+		progressMessage     *string
+		progressUpdatedAt   *time.Time
+	)
+
+	err := row.Scan(
+		&job.ID,
+		&job.Type,
+		&job.State,
+		&job.Payload,
+		&job.MaxAttempts,
+		&job.CurrentAttempt,
+		&job.TimeoutSeconds,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.StartedAt,
+		&job.CancelledAt,
+		&job.WorkerID,
+		&initialDelaySeconds,
+		&job.RetryPolicy.Multiplier,
+		&maxDelaySeconds,
+		&job.RetryPolicy.Jitter,
+		&job.RunAt,
+		&job.NextRunAt,
+		&job.ScheduleID,
+		&job.Priority,
+		&job.Queue,
+		&job.WorkflowID,
+		&job.UniqueKey,
+		&job.ConcurrencyKey,
+		&job.ConcurrencyLimit,
+		&job.ReplayOfJobID,
+		&progressPercent,
+		&progressStage,
+		&progressMessage,
+		&progressUpdatedAt,
+	)
+
+	job.RetryPolicy.InitialDelay = time.Duration(initialDelaySeconds) * time.Second
+	job.RetryPolicy.MaxDelay = time.Duration(maxDelaySeconds) * time.Second
+
+	if progressPercent != nil && progressUpdatedAt != nil {
+		job.Progress = &JobProgress{
+			Percent:   *progressPercent,
+			Stage:     progressStage,
+			Message:   progressMessage,
+			UpdatedAt: *progressUpdatedAt,
+		}
+	}
+
+	return job, err
+}
+
+// CreateJob creates a PENDING job and returns the ID of the job the caller
+// should track. That is spec.ID unless spec.UniqueKey matched an existing job
+// under the UniqueConflictReturnExisting policy.
 func (s *Store) CreateJob(
 	ctx context.Context,
-	jobID uuid.UUID,
-	jobPayload []byte,
-	maxAttempts int,
-	executionTimeoutSeconds int,
-) error {
-	_, err := s.connectionPool.Exec(
+	spec JobSpec,
+) (uuid.UUID, error) {
+	var jobID uuid.UUID
+
+	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		var err error
+		jobID, err = CreateJobTx(ctx, transaction, spec)
+		return err
+	})
+
+	return jobID, err
+}
+
+// CreateJobTx creates a PENDING job inside an existing transaction, which
+// need not belong to a Store: services sharing the orchestrator's database
+// may pass their own. The job, its audit event and its webhook deliveries
+// commit or roll back with the transaction.
+func CreateJobTx(
+	ctx context.Context,
+	transaction pgx.Tx,
+	spec JobSpec,
+) (uuid.UUID, error) {
+	return createJob(ctx, transaction, spec, JobPending, nil)
+}
+
+func createJob(
+	ctx context.Context,
+	transaction pgx.Tx,
+	spec JobSpec,
+	state string,
+	workflowID *uuid.UUID,
+) (uuid.UUID, error) {
+	retryPolicy := spec.RetryPolicy
+	if retryPolicy == (RetryPolicy{}) {
+		retryPolicy = DefaultRetryPolicy
+	}
+
+	if err := retryPolicy.Validate(); err != nil {
+		return uuid.Nil, err
+	}
+
+	queue := spec.Queue
+	if queue == "" {
+		queue = DefaultQueue
+	}
+
+	var uniqueKey *string
+	if spec.UniqueKey != "" {
+		uniqueKey = &spec.UniqueKey
+	}
+
+	var concurrencyKey *string
+	if spec.ConcurrencyKey != "" {
+		concurrencyKey = &spec.ConcurrencyKey
+	}
+
+	concurrencyLimit := spec.ConcurrencyLimit
+	if concurrencyLimit <= 0 {
+		concurrencyLimit = 1
+	}
+
+	// A replaced job frees its unique key, so the insert is attempted again
+	// after handling a conflict.
+	for {
+		var jobID uuid.UUID
+
+		err := transaction.QueryRow(
+			ctx,
+			`
+			INSERT INTO jobs (
+				id,
+				type,
+				state,
+				payload,
+				max_attempts,
+				current_attempt,
+				timeout_seconds,
+				retry_initial_delay_seconds,
+				retry_backoff_multiplier,
+				retry_max_delay_seconds,
+				retry_jitter,
+				run_at,
+				next_run_at,
+				schedule_id,
+				priority,
+				aged_priority,
+				queue,
+				workflow_id,
+				unique_key,
+				concurrency_key,
+				concurrency_limit,
+				replay_of_job_id
+			)
+			VALUES (
+				$1, $2, $3, $4, $5, 0, $6, $7, $8, $9, $10, $11, COALESCE($11, now()), $12, $13,
+				CASE WHEN $11 IS NULL OR $11 <= now() THEN $13 END,
+				$14, $15, $16, $17, $18, $19
+			)
+			ON CONFLICT (unique_key)
+				WHERE unique_key IS NOT NULL
+				  AND state NOT IN ('COMPLETED', 'FAILED', 'CANCELLED')
+				DO NOTHING
+			RETURNING id
+			`,
+			spec.ID,
+			spec.Type,
+			state,
+			spec.Payload,
+			spec.MaxAttempts,
+			spec.TimeoutSeconds,
+			int(retryPolicy.InitialDelay/time.Second),
+			retryPolicy.Multiplier,
+			int(retryPolicy.MaxDelay/time.Second),
+			retryPolicy.Jitter,
+			spec.RunAt,
+			spec.ScheduleID,
+			spec.Priority,
+			queue,
+			workflowID,
+			uniqueKey,
+			concurrencyKey,
+			concurrencyLimit,
+			spec.ReplayOfJobID,
+		).Scan(&jobID)
+		if err == nil {
+			return jobID, recordJobEvent(ctx, transaction, jobID, state, "", "created")
+		}
+		if err != pgx.ErrNoRows {
+			return uuid.Nil, err
+		}
+
+		existingID, retry, err := resolveUniqueConflict(ctx, transaction, spec)
+		if err != nil || !retry {
+			return existingID, err
+		}
+	}
+}
+
+// resolveUniqueConflict applies spec.OnConflict to the unfinished job that
+// holds spec.UniqueKey. It reports whether the insert should be retried,
+// either because the job was replaced or because it finished in the
+// meantime.
+func resolveUniqueConflict(
+	ctx context.Context,
+	transaction pgx.Tx,
+	spec JobSpec,
+) (uuid.UUID, bool, error) {
+	var (
+		existingID uuid.UUID
+		state      string
+	)
+
+	err := transaction.QueryRow(
 		ctx,
 		`
-		INSERT INTO jobs (
-			id,
-			state,
-			payload,
-			max_attempts,
-			current_attempt,
-			timeout_seconds
-		)
-		VALUES ($1, 'PENDING', $2, $3, 0, $4)
+		SELECT id, state
+		FROM jobs
+		WHERE unique_key = $1
+		  AND state NOT IN ('COMPLETED', 'FAILED', 'CANCELLED')
+		FOR UPDATE
 		`,
-		jobID,
-		jobPayload,
-		maxAttempts,
-		executionTimeoutSeconds,
-	)
+		spec.UniqueKey,
+	).Scan(&existingID, &state)
+	if err == pgx.ErrNoRows {
+		return uuid.Nil, true, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	switch spec.OnConflict {
+	case UniqueConflictReturnExisting:
+		return existingID, false, nil
 
-	return err
+	case UniqueConflictReplace:
+		if state != JobPending {
+			return existingID, false, ErrUniqueJobExists
+		}
+
+		if err := transitionJobState(ctx, transaction, existingID, JobCancelled, JobPending, "replaced by a job with the same unique key"); err != nil {
+			return uuid.Nil, false, err
+		}
+
+		if _, err := transaction.Exec(
+			ctx,
+			`UPDATE jobs SET cancelled_at = now() WHERE id = $1`,
+			existingID,
+		); err != nil {
+			return uuid.Nil, false, err
+		}
+
+		return uuid.Nil, true, nil
+
+	default:
+		return existingID, false, ErrUniqueJobExists
+	}
 }
 
 func (s *Store) CancelJob(
@@ -69,14 +393,28 @@ func (s *Store) CancelJob(
 			return err
 		}
 
-		if err := transitionJobState(ctx, tx, jobID, JobCancelled, state); err != nil {
+		if err := transitionJobState(ctx, tx, jobID, JobCancelled, state, "cancelled"); err != nil {
 			return err
 		}
 
-		_, err := tx.Exec(
+		if state == JobRunning {
+			if err := finishAttempt(ctx, tx, jobID, AttemptCancelled, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(
 			ctx,
 			`UPDATE jobs SET cancelled_at = now() WHERE id = $1`,
 			jobID,
+		); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(
+			ctx,
+			`DELETE FROM job_leases WHERE job_id = $1`,
+			jobID,
 		)
 		return err
 	})
@@ -89,36 +427,15 @@ func (s *Store) GetJobByID(
 	row := s.connectionPool.QueryRow(
 		ctx,
 		`
-			SELECT
-				id,
-				state,
-				payload,
-				max_attempts,
-				current_attempt,
-				timeout_seconds,
-				last_error,
-				created_at,
-				updated_at,
-				cancelled_at
-			FROM jobs
-			WHERE id = $1
+			SELECT `+jobColumns+`
+			FROM jobs j
+			LEFT JOIN job_leases l ON l.job_id = j.id
+			WHERE j.id = $1
 		`,
 		jobID,
 	)
-	var job Job
 
-	err := row.Scan(
-		&job.ID,
-		&job.State,
-		&job.Payload,
-		&job.MaxAttempts,
-		&job.CurrentAttempt,
-		&job.TimeoutSeconds,
-		&job.LastError,
-		&job.CreatedAt,
-		&job.UpdatedAt,
-		&job.CancelledAt,
-	)
+	job, err := scanJob(row)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -130,12 +447,16 @@ func (s *Store) GetJobByID(
 	return &job, nil
 }
 
+// transitionJobState moves a job from one state to another and records the
+// transition, with its reason and the actor carried by ctx, in the job's
+// audit log.
 func transitionJobState(
 	ctx context.Context,
 	transaction pgx.Tx,
 	jobID uuid.UUID,
 	to string,
 	from string,
+	reason string,
 ) error {
 	if err := ValidateJobTransition(from, to); err != nil {
 		return err
@@ -161,110 +482,209 @@ func transitionJobState(
 		return ErrInvalidStateTransition
 	}
 
+	if err := recordJobEvent(ctx, transaction, jobID, to, from, reason); err != nil {
+		return err
+	}
+
+	if terminalStates[to] {
+		return resolveDependents(ctx, transaction, jobID, to)
+	}
+
 	return nil
 }
 
+// transitionLeasedJobState is the fenced form of transitionJobState used on
+// behalf of a lease holder. It rejects the transition unless fencingToken
+// belongs to the job's current lease.
+func transitionLeasedJobState(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	fencingToken int64,
+	to string,
+	from string,
+	reason string,
+) error {
+	if err := checkFencingToken(ctx, transaction, jobID, fencingToken); err != nil {
+		return err
+	}
+
+	return transitionJobState(ctx, transaction, jobID, to, from, reason)
+}
+
+// checkFencingToken locks the job's lease and verifies that fencingToken
+// still holds it. A lease that expired is rejected with ErrLeaseNotHeld even
+// before it is recovered, so a holder that stalled past its lease cannot
+// report on the job.
+func checkFencingToken(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	fencingToken int64,
+) error {
+	var (
+		currentToken int64
+		expired      bool
+	)
+
+	err := transaction.QueryRow(
+		ctx,
+		`
+		SELECT fencing_token, lease_expires_at <= now()
+		FROM job_leases
+		WHERE job_id = $1
+		FOR UPDATE
+		`,
+		jobID,
+	).Scan(&currentToken, &expired)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrStaleFencingToken
+		}
+
+		return err
+	}
+
+	if currentToken != fencingToken {
+		return ErrStaleFencingToken
+	}
+
+	if expired {
+		return ErrLeaseNotHeld
+	}
+
+	return nil
+}
+
+// AcquireScheduledJobForWorker claims a SCHEDULED job of one of the given
+// types for the worker. A worker subscribed to no queues takes jobs from
+// every queue.
 func (s *Store) AcquireScheduledJobForWorker(
 	ctx context.Context,
 	workerID uuid.UUID,
-) (uuid.UUID, []byte, error) {
+	jobTypes []string,
+	queues []string,
+) (*Job, *Lease, error) {
 	var (
-		jobID   uuid.UUID
-		payload []byte
+		job   Job
+		lease Lease
 	)
 
+	if len(queues) == 0 {
+		queues = nil
+	}
+
 	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
 		row := transaction.QueryRow(ctx, `
-			SELECT j.id, j.payload
+			SELECT
+				j.id,
+				j.type,
+				j.state,
+				j.payload,
+				j.max_attempts,
+				j.current_attempt,
+				j.timeout_seconds,
+				j.queue,
+				l.scheduler_id,
+				l.fencing_token,
+				l.lease_expires_at
 			FROM jobs j
 			JOIN job_leases l ON l.job_id = j.id
 			WHERE j.state = 'SCHEDULED'
-			ORDER BY j.created_at
-			FOR UPDATE SKIP LOCKED
+			  AND j.type = ANY($1)
+			  AND ($2::text[] IS NULL OR j.queue = ANY($2))
+			  AND l.lease_expires_at > now()
+			ORDER BY j.aged_priority DESC, j.next_run_at, j.created_at
+			FOR UPDATE OF j, l SKIP LOCKED
 			LIMIT 1
-		`)
+		`, jobTypes, queues)
 
-		if err := row.Scan(&jobID, &payload); err != nil {
+		if err := row.Scan(
+			&job.ID,
+			&job.Type,
+			&job.State,
+			&job.Payload,
+			&job.MaxAttempts,
+			&job.CurrentAttempt,
+			&job.TimeoutSeconds,
+			&job.Queue,
+			&lease.HolderID,
+			&lease.FencingToken,
+			&lease.ExpiresAt,
+		); err != nil {
+			return err
+		}
+
+		lease.JobID = job.ID
+		lease.WorkerID = &workerID
+		job.WorkerID = &workerID
+
+		if _, err := transaction.Exec(
+			ctx,
+			`UPDATE job_leases SET worker_id = $2 WHERE job_id = $1`,
+			job.ID,
+			workerID,
+		); err != nil {
 			return err
 		}
 
 		if err := transitionJobState(
 			ctx,
 			transaction,
-			jobID,
-			"SCHEDULED",
-			"RUNNING",
+			job.ID,
+			JobRunning,
+			JobScheduled,
+			"picked up by worker",
 		); err != nil {
 			return err
 		}
 
-		return nil
+		job.State = JobRunning
+
+		if err := startAttempt(ctx, transaction, job.ID); err != nil {
+			return err
+		}
+
+		return transaction.QueryRow(
+			ctx,
+			`
+			UPDATE jobs
+			SET started_at = now()
+			WHERE id = $1
+			RETURNING started_at
+			`,
+			job.ID,
+		).Scan(&job.StartedAt)
 	})
 
 	if err != nil {
-		return uuid.Nil, nil, err
+		return nil, nil, err
 	}
 
-	return jobID, payload, nil
+	return &job, &lease, nil
 }
 
+// MarkJobRunning starts a SCHEDULED job for the holder of fencingToken. A
+// lease that expired is rejected with ErrLeaseNotHeld, as on every other
+// fenced path.
 func (s *Store) MarkJobRunning(
 	ctx context.Context,
 	jobID uuid.UUID,
+	fencingToken int64,
 ) error {
 	return s.WithTransaction(ctx, func(transaction pgx.Tx) error {
-		var expiresAt time.Time
-
-		err := transaction.QueryRow(
-			ctx,
-			`
-			SELECT lease_expires_at
-			FROM job_leases
-			WHERE job_id = $1
-			FOR UPDATE
-			`,
-			jobID,
-		).Scan(&expiresAt)
-		if err != nil {
+		if err := transitionLeasedJobState(ctx, transaction, jobID, fencingToken, JobRunning, JobScheduled, "started"); err != nil {
 			return err
 		}
 
-		if time.Now().After(expiresAt) {
-			return ErrInvalidStateTransition
-		}
-
-		return transitionJobState(ctx, transaction, jobID, JobRunning, JobScheduled)
-	})
-}
-
-func (s *Store) MarkJobCompleted(ctx context.Context, jobID uuid.UUID) error {
-	return s.WithTransaction(ctx, func(tx pgx.Tx) error {
-		return transitionJobState(ctx, tx, jobID, JobRunning, JobCompleted)
-	})
-}
-
-func (s *Store) MarkJobFailed(
-	ctx context.Context,
-	jobID uuid.UUID,
-	errMessage string,
-	retryable bool,
-) error {
-	return s.WithTransaction(ctx, func(tx pgx.Tx) error {
-		if err := transitionJobState(ctx, tx, jobID, JobRunning, JobFailed); err != nil {
+		if err := startAttempt(ctx, transaction, jobID); err != nil {
 			return err
 		}
 
-		_, err := tx.Exec(
+		_, err := transaction.Exec(
 			ctx,
-			`
-			UPDATE jobs
-			SET last_error = $2,
-			    retryable = $3
-			WHERE id = $1
-			`,
+			`UPDATE jobs SET started_at = now() WHERE id = $1`,
 			jobID,
-			errMessage,
-			retryable,
 		)
 		return err
 	})
@@ -309,99 +729,174 @@ func (s *Store) CountRunningJobs(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// RetryJobIfAllowed moves a RUNNING job back to PENDING when the failure is
+// retryable and attempts remain. The next attempt is delayed according to the
+// job's retry policy. It reports whether the job was retried.
 func (s *Store) RetryJobIfAllowed(
 	ctx context.Context,
 	tx pgx.Tx,
 	jobID uuid.UUID,
-	currentAttempt int,
-	maxAttempts int,
+	errMessage string,
 	retryable bool,
-) error {
+) (bool, error) {
 	if !retryable {
-		return nil
+		return false, nil
 	}
 
+	var (
+		currentAttempt      int
+		maxAttempts         int
+		initialDelaySeconds int
+		maxDelaySeconds     int
+		retryPolicy         RetryPolicy
+	)
+
+	if err := tx.QueryRow(
+		ctx,
+		`
+		SELECT
+			current_attempt,
+			max_attempts,
+			retry_initial_delay_seconds,
+			retry_backoff_multiplier,
+			retry_max_delay_seconds,
+			retry_jitter
+		FROM jobs
+		WHERE id = $1
+		FOR UPDATE
+		`,
+		jobID,
+	).Scan(
+		&currentAttempt,
+		&maxAttempts,
+		&initialDelaySeconds,
+		&retryPolicy.Multiplier,
+		&maxDelaySeconds,
+		&retryPolicy.Jitter,
+	); err != nil {
+		return false, err
+	}
+
+	retryPolicy.InitialDelay = time.Duration(initialDelaySeconds) * time.Second
+	retryPolicy.MaxDelay = time.Duration(maxDelaySeconds) * time.Second
+
 	nextAttempt := currentAttempt + 1
 
 	if nextAttempt >= maxAttempts {
-		return nil
+		return false, nil
 	}
 
-	if err := transitionJobState(ctx, tx, jobID, JobFailed, JobPending); err != nil {
-		return err
+	if err := transitionJobState(ctx, tx, jobID, JobPending, JobRunning, errMessage); err != nil {
+		return false, err
 	}
 
 	_, err := tx.Exec(
 		ctx,
 		`
 		UPDATE jobs
-		SET current_attempt = $2
+		SET current_attempt = $2,
+			last_error = $3,
+			next_run_at = $4,
+			aged_priority = CASE WHEN $4 <= now() THEN priority END,
+			started_at = NULL
 		WHERE id = $1
 		`,
 		jobID,
 		nextAttempt,
+		errMessage,
+		time.Now().Add(retryPolicy.Backoff(nextAttempt)),
 	)
-	return err
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`
+		DELETE FROM job_leases
+		WHERE job_id = $1
+		`,
+		jobID,
+	)
+
+	return err == nil, err
+}
+
+// ListJobsFilter narrows ListJobs. Delayed selects PENDING jobs whose
+// next run is still in the future. Jobs are listed newest first unless
+// SortByPriority is set.
+type ListJobsFilter struct {
+	State          *string
+	Queue          *string
+	Delayed        bool
+	MinPriority    *int
+	MaxPriority    *int
+	SortByPriority bool
+	Limit          int
 }
 
 func (s *Store) ListJobs(
 	ctx context.Context,
-	state *string,
-	limit int,
+	filter ListJobsFilter,
 ) ([]Job, error) {
+	limit := filter.Limit
 	if limit <= 0 {
 		limit = 100
 	}
 
-	var rows pgx.Rows
-	var err error
+	var (
+		conditions []string
+		args       []any
+	)
 
-	if state != nil {
-		rows, err = s.connectionPool.Query(
-			ctx,
-			`
-			SELECT 
-				id,
-				state,
-				payload,
-				max_attempts,
-				current_attempt,
-				timeout_seconds,
-				last_error,
-				created_at,
-				updated_at,
-				cancelled_at
-			FROM jobs
-			WHERE state = $1
-			ORDER BY created_at DESC
-			LIMIT $2
-			`,
-			*state,
-			limit,
-		)
-	} else {
-		rows, err = s.connectionPool.Query(
-			ctx,
-			`
-			SELECT
-				id,
-				state,
-				payload,
-				max_attempts,
-				current_attempt,
-				timeout_seconds,
-				last_error,
-				created_at,
-				updated_at,
-				cancelled_at
-			FROM jobs
-			ORDER BY created_at DESC
-			LIMIT $1
-			`,
-			limit,
-		)
+	if filter.State != nil {
+		args = append(args, *filter.State)
+		conditions = append(conditions, fmt.Sprintf("j.state = $%d", len(args)))
+	}
+
+	if filter.Queue != nil {
+		args = append(args, *filter.Queue)
+		conditions = append(conditions, fmt.Sprintf("j.queue = $%d", len(args)))
 	}
 
+	if filter.Delayed {
+		conditions = append(conditions, "j.state = 'PENDING' AND j.next_run_at > now()")
+	}
+
+	if filter.MinPriority != nil {
+		args = append(args, *filter.MinPriority)
+		conditions = append(conditions, fmt.Sprintf("j.priority >= $%d", len(args)))
+	}
+
+	if filter.MaxPriority != nil {
+		args = append(args, *filter.MaxPriority)
+		conditions = append(conditions, fmt.Sprintf("j.priority <= $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	orderBy := "j.created_at DESC"
+	if filter.SortByPriority {
+		orderBy = "j.priority DESC, j.created_at DESC"
+	}
+
+	args = append(args, limit)
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT `+jobColumns+`
+		FROM jobs j
+		LEFT JOIN job_leases l ON l.job_id = j.id
+		`+where+`
+		ORDER BY `+orderBy+`
+		LIMIT `+fmt.Sprintf("$%d", len(args)),
+		args...,
+	)
+
 	if err != nil {
 		return nil, err
 	}
@@ -410,19 +905,8 @@ func (s *Store) ListJobs(
 	var jobs []Job
 
 	for rows.Next() {
-		var job Job
-		if err := rows.Scan(
-			&job.ID,
-			&job.State,
-			&job.Payload,
-			&job.MaxAttempts,
-			&job.CurrentAttempt,
-			&job.TimeoutSeconds,
-			&job.LastError,
-			&job.CreatedAt,
-			&job.UpdatedAt,
-			&job.CancelledAt,
-		); err != nil {
+		job, err := scanJob(rows)
+		if err != nil {
 			return nil, err
 		}
 
@@ -436,21 +920,38 @@ func (s *Store) ListJobs(
 	return jobs, nil
 }
 
+// CompleteJob marks a RUNNING job COMPLETED. When result is non-nil, its
+// Result and ExpiresAt are stored as the job's result in the same
+// transaction.
 func (s *Store) CompleteJob(
 	ctx context.Context,
 	jobID uuid.UUID,
+	fencingToken int64,
+	result *JobResult,
 ) error {
 	return s.WithTransaction(ctx, func(transaction pgx.Tx) error {
-		if err := transitionJobState(
+		if err := transitionLeasedJobState(
 			ctx,
 			transaction,
 			jobID,
+			fencingToken,
 			JobCompleted,
 			JobRunning,
+			"completed",
 		); err != nil {
 			return err
 		}
 
+		if err := finishAttempt(ctx, transaction, jobID, AttemptCompleted, nil, nil); err != nil {
+			return err
+		}
+
+		if result != nil {
+			if err := storeJobResult(ctx, transaction, jobID, result.Result, result.ExpiresAt); err != nil {
+				return err
+			}
+		}
+
 		_, err := transaction.Exec(
 			ctx,
 			`
@@ -464,48 +965,162 @@ func (s *Store) CompleteJob(
 	})
 }
 
+// FailJob records a failed attempt of a RUNNING job and returns the state
+// the job moved to: PENDING when it will be retried, FAILED otherwise.
 func (s *Store) FailJob(
 	ctx context.Context,
 	jobID uuid.UUID,
+	fencingToken int64,
 	errMessage string,
 	retryable bool,
-) error {
-	return s.WithTransaction(ctx, func(transaction pgx.Tx) error {
-		if err := transitionJobState(
-			ctx,
-			transaction,
-			jobID,
-			JobFailed,
-			JobRunning,
-		); err != nil {
+) (string, error) {
+	var state string
+
+	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		if err := checkFencingToken(ctx, transaction, jobID, fencingToken); err != nil {
 			return err
 		}
 
-		_, err := transaction.Exec(
+		var err error
+		state, err = s.failRunningJob(ctx, transaction, jobID, AttemptFailed, errMessage, retryable)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return state, nil
+}
+
+func (s *Store) FailTimedOutJobs(
+	ctx context.Context,
+	now time.Time,
+) ([]uuid.UUID, error) {
+	var timedOut []uuid.UUID
+
+	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		rows, err := transaction.Query(
 			ctx,
 			`
-			UPDATE jobs
-			SET last_error = $2,
-				retryable = $3
-			WHERE id = $1
+			SELECT id, timeout_seconds
+			FROM jobs
+			WHERE state = 'RUNNING'
+			  AND started_at + timeout_seconds * interval '1 second' < $1
+			FOR UPDATE SKIP LOCKED
 			`,
-			jobID,
-			errMessage,
-			retryable,
+			now,
 		)
 		if err != nil {
 			return err
 		}
 
-		_, err = transaction.Exec(
-			ctx,
-			`
-			DELETE FROM job_leases
-			WHERE job_id = $1
-			`,
-			jobID,
-		)
+		type expiredJob struct {
+			id             uuid.UUID
+			timeoutSeconds int
+		}
 
-		return err
+		var expired []expiredJob
+		for rows.Next() {
+			var job expiredJob
+			if err := rows.Scan(&job.id, &job.timeoutSeconds); err != nil {
+				rows.Close()
+				return err
+			}
+			expired = append(expired, job)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, job := range expired {
+			if _, err := s.failRunningJob(
+				ctx,
+				transaction,
+				job.id,
+				AttemptTimedOut,
+				fmt.Sprintf("job exceeded timeout of %ds", job.timeoutSeconds),
+				true,
+			); err != nil {
+				return err
+			}
+
+			timedOut = append(timedOut, job.id)
+		}
+
+		return nil
 	})
+
+	return timedOut, err
+}
+
+// failRunningJob closes the current attempt with the given outcome and
+// error, then retries the job if its policy allows it and otherwise moves it
+// to FAILED and files it as a dead letter. It returns the state the job
+// moved to.
+func (s *Store) failRunningJob(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	outcome string,
+	errMessage string,
+	retryable bool,
+) (string, error) {
+	if err := finishAttempt(ctx, transaction, jobID, outcome, &errMessage, &retryable); err != nil {
+		return "", err
+	}
+
+	retried, err := s.RetryJobIfAllowed(ctx, transaction, jobID, errMessage, retryable)
+	if err != nil {
+		return "", err
+	}
+	if retried {
+		return JobPending, nil
+	}
+
+	if err := transitionJobState(
+		ctx,
+		transaction,
+		jobID,
+		JobFailed,
+		JobRunning,
+		errMessage,
+	); err != nil {
+		return "", err
+	}
+
+	_, err = transaction.Exec(
+		ctx,
+		`
+		UPDATE jobs
+		SET last_error = $2,
+			retryable = $3
+		WHERE id = $1
+		`,
+		jobID,
+		errMessage,
+		retryable,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := deadLetterJob(ctx, transaction, jobID); err != nil {
+		return "", err
+	}
+
+	_, err = transaction.Exec(
+		ctx,
+		`
+		DELETE FROM job_leases
+		WHERE job_id = $1
+		`,
+		jobID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return JobFailed, nil
 }
@@ -14,7 +14,7 @@ func TestCancelPendingJob(t *testing.T) {
 	store := newTestStore(t)
 
 	jobID := uuid.New()
-	if err := store.CreateJob(ctx, jobID, []byte(`{}`), 3, 30); err != nil {
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
 		t.Fatal(err)
 	}
 
@@ -32,18 +32,18 @@ func TestCancelCompletedJobFails(t *testing.T) {
 	store := newTestStore(t)
 
 	jobID := uuid.New()
-	if err := store.CreateJob(ctx, jobID, []byte(`{}`), 3, 30); err != nil {
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
 		t.Fatal(err)
 	}
 
 	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
-		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending); err != nil {
+		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending, ""); err != nil {
 			return err
 		}
-		if err := transitionJobState(ctx, tx, jobID, JobRunning, JobScheduled); err != nil {
+		if err := transitionJobState(ctx, tx, jobID, JobRunning, JobScheduled, ""); err != nil {
 			return err
 		}
-		return transitionJobState(ctx, tx, jobID, JobCompleted, JobRunning)
+		return transitionJobState(ctx, tx, jobID, JobCompleted, JobRunning, "")
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -59,28 +59,189 @@ func TestFailedJobCannotRun(t *testing.T) {
 	store := newTestStore(t)
 
 	jobID := uuid.New()
-	if err := store.CreateJob(ctx, jobID, []byte(`{}`), 3, 30); err != nil {
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
 		t.Fatal(err)
 	}
 
 	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
-		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending); err != nil {
+		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending, ""); err != nil {
 			return err
 		}
-		if err := transitionJobState(ctx, tx, jobID, JobRunning, JobScheduled); err != nil {
+		if err := transitionJobState(ctx, tx, jobID, JobRunning, JobScheduled, ""); err != nil {
 			return err
 		}
-		return transitionJobState(ctx, tx, jobID, JobFailed, JobRunning)
+		return transitionJobState(ctx, tx, jobID, JobFailed, JobRunning, "")
 	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	err = store.WithTransaction(ctx, func(tx pgx.Tx) error {
-		return transitionJobState(ctx, tx, jobID, JobRunning, JobFailed)
+		return transitionJobState(ctx, tx, jobID, JobRunning, JobFailed, "")
 	})
 
 	if !errors.Is(err, ErrInvalidStateTransition) {
 		t.Fatalf("expected ErrInvalidStateTransition, got %v", err)
 	}
 }
+
+func TestRetryableFailureIsDelayed(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := store.FailJob(ctx, jobID, fencingToken, "downstream unavailable", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state != JobPending {
+		t.Fatalf("expected FailJob to report %s, got %s", JobPending, state)
+	}
+
+	job, err := store.GetJobByID(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.State != JobPending {
+		t.Fatalf("expected %s, got %s", JobPending, job.State)
+	}
+
+	if job.CurrentAttempt != 1 {
+		t.Fatalf("expected attempt 1, got %d", job.CurrentAttempt)
+	}
+
+	if !job.NextRunAt.After(job.UpdatedAt) {
+		t.Fatalf("expected next run after %s, got %s", job.UpdatedAt, job.NextRunAt)
+	}
+}
+
+func TestListJobsByPriority(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	// A queue of its own keeps jobs created by other tests out of the list.
+	queue := "priority-" + uuid.NewString()
+
+	lowJobID := uuid.New()
+	lowSpec := newTestJobSpec(lowJobID)
+	lowSpec.Queue = queue
+	lowSpec.Priority = 1
+	if _, err := store.CreateJob(ctx, lowSpec); err != nil {
+		t.Fatal(err)
+	}
+
+	highJobID := uuid.New()
+	highSpec := newTestJobSpec(highJobID)
+	highSpec.Queue = queue
+	highSpec.Priority = 5
+	if _, err := store.CreateJob(ctx, highSpec); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := store.ListJobs(ctx, ListJobsFilter{
+		Queue:          &queue,
+		SortByPriority: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(jobs) != 2 || jobs[0].ID != highJobID || jobs[1].ID != lowJobID {
+		t.Fatalf("expected high priority job first, got %+v", jobs)
+	}
+}
+
+func TestUniqueKeyConflictPolicies(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	uniqueKey := "unique-" + uuid.NewString()
+
+	firstSpec := newTestJobSpec(uuid.New())
+	firstSpec.UniqueKey = uniqueKey
+	if _, err := store.CreateJob(ctx, firstSpec); err != nil {
+		t.Fatal(err)
+	}
+
+	rejectedSpec := newTestJobSpec(uuid.New())
+	rejectedSpec.UniqueKey = uniqueKey
+	if _, err := store.CreateJob(ctx, rejectedSpec); !errors.Is(err, ErrUniqueJobExists) {
+		t.Fatalf("expected ErrUniqueJobExists, got %v", err)
+	}
+
+	existingSpec := newTestJobSpec(uuid.New())
+	existingSpec.UniqueKey = uniqueKey
+	existingSpec.OnConflict = UniqueConflictReturnExisting
+	jobID, err := store.CreateJob(ctx, existingSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobID != firstSpec.ID {
+		t.Fatalf("expected existing job %s, got %s", firstSpec.ID, jobID)
+	}
+
+	replacementSpec := newTestJobSpec(uuid.New())
+	replacementSpec.UniqueKey = uniqueKey
+	replacementSpec.OnConflict = UniqueConflictReplace
+	jobID, err = store.CreateJob(ctx, replacementSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobID != replacementSpec.ID {
+		t.Fatalf("expected replacement job %s, got %s", replacementSpec.ID, jobID)
+	}
+
+	replaced, err := store.GetJobByID(ctx, firstSpec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced.State != JobCancelled {
+		t.Fatalf("expected replaced job to be %s, got %s", JobCancelled, replaced.State)
+	}
+}
+
+func TestCreateJobTxFollowsCallerTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	errRollback := errors.New("rollback")
+	rolledBackSpec := newTestJobSpec(uuid.New())
+
+	err := store.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		if _, err := CreateJobTx(ctx, transaction, rolledBackSpec); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("expected rollback error, got %v", err)
+	}
+
+	job, err := store.GetJobByID(ctx, rolledBackSpec.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job != nil {
+		t.Fatalf("expected rolled back job to not exist, got %+v", job)
+	}
+
+	committedSpec := newTestJobSpec(uuid.New())
+
+	err = store.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		_, err := CreateJobTx(ctx, transaction, committedSpec)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertJobState(t, store, committedSpec.ID, JobPending)
+}
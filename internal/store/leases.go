@@ -2,129 +2,467 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+// DefaultLeaseDuration is how long a lease is valid before it must be renewed.
+const DefaultLeaseDuration = 30 * time.Second
+
+// PriorityAgingInterval is how long a due job waits before its aged
+// priority rises by one. Aging is unbounded, so low-priority jobs are never
+// starved by a steady stream of high-priority ones. AgePendingJobs applies
+// it and should run about once per interval.
+const PriorityAgingInterval = time.Minute
+
+// AgePendingJobs brings the aged priority of every PENDING job up to date
+// and reports how many jobs changed. Leasing orders jobs by aged priority.
+// Jobs locked by a scheduler that is leasing them are skipped. A job has no
+// aged priority until it is due, so that leasing never reads past future
+// jobs; see promoteDueJobs.
+func (s *Store) AgePendingJobs(ctx context.Context) (int64, error) {
+	agedPriority := fmt.Sprintf(
+		"j.priority + FLOOR(EXTRACT(EPOCH FROM now() - j.next_run_at) / %d)::int",
+		int(PriorityAgingInterval/time.Second),
+	)
+
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`
+		WITH due AS (
+			SELECT j.id
+			FROM jobs j
+			WHERE j.state = 'PENDING'
+			  AND j.next_run_at <= now() - $1 * interval '1 second'
+			  AND j.aged_priority IS DISTINCT FROM `+agedPriority+`
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE jobs j
+		SET aged_priority = `+agedPriority+`
+		FROM due
+		WHERE j.id = due.id
+		`,
+		int(PriorityAgingInterval/time.Second),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// Lease is a time-bound claim on a job. FencingToken increases with every
+// lease ever granted; holders must present it when reporting on the job so
+// that a holder whose lease was recovered cannot act on it anymore.
+type Lease struct {
+	JobID        uuid.UUID
+	HolderID     uuid.UUID
+	WorkerID     *uuid.UUID
+	FencingToken int64
+	ExpiresAt    time.Time
+}
+
+// promoteBatchSize is how many newly due jobs promoteDueJobs gives an aged
+// priority per lease attempt.
+const promoteBatchSize = 100
+
+// promoteDueJobs gives PENDING jobs that have become due their priority as
+// aged priority, which puts them in the order leasing reads. Jobs another
+// scheduler is promoting are skipped; they are visible once it commits.
+func promoteDueJobs(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		UPDATE jobs
+		SET aged_priority = priority
+		WHERE id IN (
+			SELECT id
+			FROM jobs
+			WHERE state = 'PENDING'
+			  AND aged_priority IS NULL
+			  AND next_run_at <= now()
+			ORDER BY next_run_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		`,
+		promoteBatchSize,
+	)
+
+	return err
+}
+
+// leaseCandidateBatchSize is how many due jobs AcquireJobLease considers per
+// attempt. A candidate whose queue or concurrency key filled up meanwhile, or
+// that another scheduler is leasing, is passed over in favour of the next one.
+const leaseCandidateBatchSize = 10
+
+type leaseCandidate struct {
+	jobID            uuid.UUID
+	queue            string
+	concurrencyKey   *string
+	concurrencyLimit int
+}
+
 func (s *Store) AcquireJobLease(
 	ctx context.Context,
 	schedulerID uuid.UUID,
 	leaseDuration time.Duration,
-) (uuid.UUID, time.Time, error) {
-	var jobID uuid.UUID
-	leaseExpiresAt := time.Now().Add(leaseDuration)
+) (*Lease, error) {
+	lease := Lease{
+		HolderID:  schedulerID,
+		ExpiresAt: time.Now().Add(leaseDuration),
+	}
 
 	err := s.WithTransaction(ctx, func(tx pgx.Tx) error {
-		if err := tx.QueryRow(
+		if err := promoteDueJobs(ctx, tx); err != nil {
+			return err
+		}
+
+		// Saturated queues and concurrency keys are skipped up front; each
+		// candidate is checked again under lock because another scheduler
+		// may be filling the last slot. Candidates are read without locking,
+		// and only the one being leased is locked. A queue is also skipped while its
+		// SCHEDULED jobs already fill the free capacity of the live workers
+		// serving it, so no job is leased that no worker can pick up.
+		rows, err := tx.Query(
 			ctx,
 			`
-			SELECT id
-			FROM jobs
-			WHERE state = 'PENDING'
-			ORDER BY created_at
-			FOR UPDATE SKIP LOCKED
-			LIMIT 1
+			WITH live_workers AS (
+				SELECT w.queues, w.capacity - COUNT(r.id) AS free_capacity
+				FROM workers w
+				LEFT JOIN job_leases l ON l.worker_id = w.id
+				LEFT JOIN jobs r ON r.id = l.job_id AND r.state = 'RUNNING'
+				WHERE w.last_heartbeat > now() - interval '15 seconds'
+				GROUP BY w.id
+			)
+			SELECT j.id, j.queue, j.concurrency_key, j.concurrency_limit
+			FROM jobs j
+			LEFT JOIN queues q ON q.name = j.queue
+			WHERE j.state = 'PENDING'
+			  AND j.aged_priority IS NOT NULL
+			  AND j.next_run_at <= now()
+			  AND (
+				SELECT COALESCE(SUM(GREATEST(w.free_capacity, 0)), 0)
+				FROM live_workers w
+				WHERE w.queues IS NULL OR j.queue = ANY(w.queues)
+			  ) > (
+				SELECT COUNT(*)
+				FROM jobs a
+				WHERE a.queue = j.queue
+				  AND a.state = 'SCHEDULED'
+			  )
+			  AND (
+				q.max_concurrency IS NULL
+				OR q.max_concurrency > (
+					SELECT COUNT(*)
+					FROM jobs a
+					WHERE a.queue = j.queue
+					  AND a.state IN ('SCHEDULED', 'RUNNING')
+				)
+			  )
+			  AND (
+				j.concurrency_key IS NULL
+				OR (
+					SELECT COUNT(*) < LEAST(
+						j.concurrency_limit,
+						COALESCE(MIN(a.concurrency_limit), j.concurrency_limit)
+					)
+					FROM jobs a
+					WHERE a.concurrency_key = j.concurrency_key
+					  AND a.state IN ('SCHEDULED', 'RUNNING')
+				)
+			  )
+			ORDER BY j.aged_priority DESC, j.next_run_at, j.created_at
+			LIMIT $1
 			`,
-		).Scan(&jobID); err != nil {
+			leaseCandidateBatchSize,
+		)
+		if err != nil {
 			return err
 		}
 
-		if err := transitionJobState(
-			ctx,
-			tx,
-			jobID,
-			"PENDING",
-			"SCHEDULED",
-		); err != nil {
+		var candidates []leaseCandidate
+
+		for rows.Next() {
+			var candidate leaseCandidate
+
+			if err := rows.Scan(
+				&candidate.jobID,
+				&candidate.queue,
+				&candidate.concurrencyKey,
+				&candidate.concurrencyLimit,
+			); err != nil {
+				rows.Close()
+				return err
+			}
+
+			candidates = append(candidates, candidate)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
 			return err
 		}
 
-		_, err := tx.Exec(
-			ctx,
-			`
-			INSERT INTO job_leases (
-				job_id,
-				scheduler_id,
-				lease_expires_at
-			)
-			VALUES ($1, $2, $3)
-			`,
-			jobID,
-			schedulerID,
-			leaseExpiresAt,
-		)
-		return err
+		for _, candidate := range candidates {
+			available, err := reserveQueueSlot(ctx, tx, candidate.queue)
+			if err != nil {
+				return err
+			}
+			if !available {
+				continue
+			}
+
+			if candidate.concurrencyKey != nil {
+				available, err := reserveConcurrencySlot(
+					ctx,
+					tx,
+					*candidate.concurrencyKey,
+					candidate.concurrencyLimit,
+				)
+				if err != nil {
+					return err
+				}
+				if !available {
+					continue
+				}
+			}
+
+			err = tx.QueryRow(
+				ctx,
+				`
+				SELECT id
+				FROM jobs
+				WHERE id = $1
+				  AND state = 'PENDING'
+				FOR UPDATE SKIP LOCKED
+				`,
+				candidate.jobID,
+			).Scan(&lease.JobID)
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := transitionJobState(
+				ctx,
+				tx,
+				lease.JobID,
+				JobScheduled,
+				JobPending,
+				"lease acquired",
+			); err != nil {
+				return err
+			}
+
+			return tx.QueryRow(
+				ctx,
+				`
+				INSERT INTO job_leases (
+					job_id,
+					scheduler_id,
+					lease_expires_at
+				)
+				VALUES ($1, $2, $3)
+				RETURNING fencing_token
+				`,
+				lease.JobID,
+				schedulerID,
+				lease.ExpiresAt,
+			).Scan(&lease.FencingToken)
+		}
+
+		return pgx.ErrNoRows
 	})
 
 	if err != nil {
-		return uuid.Nil, time.Time{}, err
+		return nil, err
+	}
+
+	return &lease, nil
+}
+
+// Advisory lock classes keep the locks on queue names and concurrency keys
+// apart, so that a queue and a key sharing a name do not contend.
+const (
+	advisoryLockQueue int32 = iota + 1
+	advisoryLockConcurrencyKey
+)
+
+// tryAdvisoryLock takes a transaction-scoped advisory lock on name within
+// class without waiting, and reports whether it was taken.
+func tryAdvisoryLock(
+	ctx context.Context,
+	tx pgx.Tx,
+	class int32,
+	name string,
+) (bool, error) {
+	var locked bool
+
+	err := tx.QueryRow(
+		ctx,
+		`SELECT pg_try_advisory_xact_lock($1, hashtext($2))`,
+		class,
+		name,
+	).Scan(&locked)
+
+	return locked, err
+}
+
+// reserveConcurrencySlot reports whether fewer jobs holding the concurrency
+// key are SCHEDULED or RUNNING than the strictest limit among them and the
+// candidate's limit. Schedulers checking the same key serialize on a
+// transaction-scoped advisory lock; a scheduler that cannot take the lock
+// immediately treats the key as saturated rather than wait.
+func reserveConcurrencySlot(
+	ctx context.Context,
+	tx pgx.Tx,
+	concurrencyKey string,
+	limit int,
+) (bool, error) {
+	locked, err := tryAdvisoryLock(ctx, tx, advisoryLockConcurrencyKey, concurrencyKey)
+	if err != nil || !locked {
+		return false, err
+	}
+
+	var (
+		active      int
+		activeLimit *int
+	)
+
+	if err := tx.QueryRow(
+		ctx,
+		`
+		SELECT COUNT(*), MIN(concurrency_limit)
+		FROM jobs
+		WHERE concurrency_key = $1
+		  AND state IN ('SCHEDULED', 'RUNNING')
+		`,
+		concurrencyKey,
+	).Scan(&active, &activeLimit); err != nil {
+		return false, err
+	}
+
+	if activeLimit != nil && *activeLimit < limit {
+		limit = *activeLimit
+	}
+
+	return active < limit, nil
+}
+
+func (s *Store) RenewLease(
+	ctx context.Context,
+	jobID uuid.UUID,
+	holderID uuid.UUID,
+	fencingToken int64,
+	extension time.Duration,
+) (time.Time, error) {
+	now := time.Now()
+	leaseExpiresAt := now.Add(extension)
+
+	err := s.connectionPool.QueryRow(
+		ctx,
+		`
+		UPDATE job_leases
+		SET lease_expires_at = $4
+		WHERE job_id = $1
+		  AND scheduler_id = $2
+		  AND fencing_token = $3
+		  AND lease_expires_at > $5
+		RETURNING lease_expires_at
+		`,
+		jobID,
+		holderID,
+		fencingToken,
+		leaseExpiresAt,
+		now,
+	).Scan(&leaseExpiresAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return time.Time{}, ErrLeaseNotHeld
+		}
+
+		return time.Time{}, err
 	}
 
-	return jobID, leaseExpiresAt, nil
+	return leaseExpiresAt, nil
 }
 
 func (s *Store) RecoverExpiredLeases(
 	ctx context.Context,
 	now time.Time,
 ) ([]uuid.UUID, error) {
+	return s.recoverLeases(ctx, `l.lease_expires_at < $1`, now)
+}
+
+// RecoverWorkerLeases releases every lease claimed by workerID, regardless of
+// expiry. It is used when a worker is known to be gone.
+func (s *Store) RecoverWorkerLeases(
+	ctx context.Context,
+	workerID uuid.UUID,
+) ([]uuid.UUID, error) {
+	return s.recoverLeases(ctx, `l.worker_id = $1`, workerID)
+}
+
+func (s *Store) recoverLeases(
+	ctx context.Context,
+	condition string,
+	args ...any,
+) ([]uuid.UUID, error) {
+	type leasedJob struct {
+		id    uuid.UUID
+		state string
+	}
+
 	var recovered []uuid.UUID
 
 	err := s.WithTransaction(ctx, func(tx pgx.Tx) error {
 		rows, err := tx.Query(ctx, `
 			SELECT
 				j.id,
-				j.state,
-				j.current_attempt,
-				j.max_attempts,
-				j.retryable
+				j.state
 			FROM job_leases l
 			JOIN jobs j ON j.id = l.job_id
-			WHERE l.lease_expires_at < $1
+			WHERE `+condition+`
 			  AND j.state NOT IN ('COMPLETED', 'FAILED', 'CANCELLED')
 			FOR UPDATE SKIP LOCKED
-		`, now)
+		`, args...)
 		if err != nil {
 			return err
 		}
-		defer rows.Close()
 
+		var leased []leasedJob
 		for rows.Next() {
-			var (
-				jobID          uuid.UUID
-				state          string
-				currentAttempt int
-				maxAttempts    int
-				retryable      bool
-			)
+			var job leasedJob
 
-			if err := rows.Scan(
-				&jobID,
-				&state,
-				&currentAttempt,
-				&maxAttempts,
-				&retryable,
-			); err != nil {
+			if err := rows.Scan(&job.id, &job.state); err != nil {
+				rows.Close()
 				return err
 			}
 
-			if err := s.recoverSingleJob(
-				ctx,
-				tx,
-				jobID,
-				state,
-				currentAttempt,
-				maxAttempts,
-				retryable,
-			); err != nil {
+			leased = append(leased, job)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, job := range leased {
+			if err := s.recoverSingleJob(ctx, tx, job.id, job.state); err != nil {
 				return err
 			}
 
-			recovered = append(recovered, jobID)
+			recovered = append(recovered, job.id)
 		}
 
-		return rows.Err()
+		return nil
 	})
 
 	return recovered, err
@@ -135,24 +473,14 @@ func (s *Store) recoverSingleJob(
 	tx pgx.Tx,
 	jobID uuid.UUID,
 	state string,
-	currentAttempt int,
-	maxAttempts int,
-	retryable bool,
 ) error {
 	switch state {
-	case "SCHEDULED":
-		if err := transitionJobState(ctx, tx, jobID, "SCHEDULED", "PENDING"); err != nil {
+	case JobScheduled:
+		if err := transitionJobState(ctx, tx, jobID, JobPending, JobScheduled, "lease recovered before start"); err != nil {
 			return err
 		}
-	case "RUNNING":
-		if err := s.RetryJobIfAllowed(
-			ctx,
-			tx,
-			jobID,
-			currentAttempt,
-			maxAttempts,
-			retryable,
-		); err != nil {
+	case JobRunning:
+		if _, err := s.failRunningJob(ctx, tx, jobID, AttemptLeaseExpired, "job lease recovered while running", true); err != nil {
 			return err
 		}
 	}
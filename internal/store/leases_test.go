@@ -0,0 +1,628 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func scheduleTestJob(t *testing.T, store *Store, jobID uuid.UUID) int64 {
+	t.Helper()
+
+	return scheduleTestJobSpec(t, store, newTestJobSpec(jobID))
+}
+
+// scheduleTestJobSpec creates the job of spec and leases it as a scheduler
+// would, returning the lease's fencing token.
+func scheduleTestJobSpec(t *testing.T, store *Store, spec JobSpec) int64 {
+	t.Helper()
+
+	ctx := context.Background()
+	jobID := spec.ID
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	var fencingToken int64
+
+	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending, ""); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(
+			ctx,
+			`
+			INSERT INTO job_leases (job_id, scheduler_id, lease_expires_at)
+			VALUES ($1, $2, now() + interval '30 seconds')
+			RETURNING fencing_token
+			`,
+			jobID,
+			uuid.New(),
+		).Scan(&fencingToken)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fencingToken
+}
+
+func TestStaleFencingTokenRejected(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken-1); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.CompleteJob(ctx, jobID, fencingToken+1, nil); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+
+	if err := store.CompleteJob(ctx, jobID, fencingToken, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFailJobWithoutLeaseRejected(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.connectionPool.Exec(ctx, `DELETE FROM job_leases WHERE job_id = $1`, jobID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.FailJob(ctx, jobID, fencingToken, "boom", false); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected ErrStaleFencingToken, got %v", err)
+	}
+}
+
+func TestConcurrencyKeyLimitEnforced(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	concurrencyKey := "account-" + uuid.NewString()
+
+	jobID := uuid.New()
+	spec := newTestJobSpec(jobID)
+	spec.ConcurrencyKey = concurrencyKey
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		available, err := reserveConcurrencySlot(ctx, tx, concurrencyKey, 1)
+		if err != nil {
+			return err
+		}
+		if !available {
+			t.Fatal("expected a free slot")
+		}
+
+		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending, ""); err != nil {
+			return err
+		}
+
+		available, err = reserveConcurrencySlot(ctx, tx, concurrencyKey, 1)
+		if err != nil {
+			return err
+		}
+		if available {
+			t.Fatal("expected the concurrency key to be saturated")
+		}
+
+		// The active job allows one at a time, which a laxer limit of a
+		// later job of the key does not override.
+		available, err = reserveConcurrencySlot(ctx, tx, concurrencyKey, 2)
+		if err != nil {
+			return err
+		}
+		if available {
+			t.Fatal("expected the strictest limit of the key to apply")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExpiredLeaseHolderCannotStart(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE job_leases SET lease_expires_at = now() - interval '1 second' WHERE job_id = $1`,
+		jobID,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld, got %v", err)
+	}
+
+	assertJobState(t, store, jobID, JobScheduled)
+}
+
+func TestExpiredLeaseHolderCannotComplete(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE job_leases SET lease_expires_at = now() - interval '1 second' WHERE job_id = $1`,
+		jobID,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CompleteJob(ctx, jobID, fencingToken, nil); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld, got %v", err)
+	}
+
+	assertJobState(t, store, jobID, JobRunning)
+}
+
+// leaseTestPriority lies far above the priority of any job other tests leave
+// behind, aged or not, so that the jobs of lease order tests are leased
+// first.
+const leaseTestPriority = 1_000_000_000
+
+// leaseTestJobSpec describes a job in queue at the given offset above
+// leaseTestPriority that became due waited ago.
+func leaseTestJobSpec(queue string, priority int, waited time.Duration) JobSpec {
+	spec := newTestJobSpec(uuid.New())
+	spec.Queue = queue
+	spec.Priority = leaseTestPriority + priority
+	runAt := time.Now().Add(-waited)
+	spec.RunAt = &runAt
+
+	return spec
+}
+
+// createLeaseTestJob creates the job of spec. It is cancelled when the test
+// ends so that it cannot outrank the jobs of later tests.
+func createLeaseTestJob(t *testing.T, store *Store, spec JobSpec) uuid.UUID {
+	t.Helper()
+
+	if _, err := store.CreateJob(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = store.CancelJob(context.Background(), spec.ID)
+	})
+
+	return spec.ID
+}
+
+// registerTestWorker registers a live worker serving queues, so that jobs of
+// those queues can be leased.
+func registerTestWorker(t *testing.T, store *Store, capacity int, queues ...string) {
+	t.Helper()
+
+	if err := store.RegisterWorker(context.Background(), uuid.New(), capacity, queues); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func acquireTestLease(t *testing.T, store *Store) uuid.UUID {
+	t.Helper()
+
+	lease, err := store.AcquireJobLease(context.Background(), uuid.New(), DefaultLeaseDuration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return lease.JobID
+}
+
+func TestLeaseOrderFollowsPriority(t *testing.T) {
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 2, queue)
+
+	lowJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 1, 0))
+	highJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 5, 0))
+
+	if jobID := acquireTestLease(t, store); jobID != highJobID {
+		t.Fatalf("expected high priority job %s to be leased first, got %s", highJobID, jobID)
+	}
+
+	if jobID := acquireTestLease(t, store); jobID != lowJobID {
+		t.Fatalf("expected low priority job %s to be leased next, got %s", lowJobID, jobID)
+	}
+}
+
+func TestAgingPreventsStarvation(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 1, queue)
+
+	// Waiting ten minutes lifts the low priority job five steps above the
+	// fresh high priority one.
+	starvedJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 0, 10*PriorityAgingInterval))
+	createLeaseTestJob(t, store, leaseTestJobSpec(queue, 5, 0))
+
+	if _, err := store.AgePendingJobs(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := store.GetJobByID(ctx, starvedJobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Priority != leaseTestPriority {
+		t.Fatalf("expected aging to leave the priority alone, got %d", job.Priority)
+	}
+
+	if jobID := acquireTestLease(t, store); jobID != starvedJobID {
+		t.Fatalf("expected aged job %s to be leased first, got %s", starvedJobID, jobID)
+	}
+}
+
+func TestLeaseWaitsForServingWorker(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	jobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 0, 0))
+
+	// No live worker serves the queue yet, so another job or none is leased.
+	lease, err := store.AcquireJobLease(ctx, uuid.New(), DefaultLeaseDuration)
+	if err != nil && err != pgx.ErrNoRows {
+		t.Fatal(err)
+	}
+	if err == nil && lease.JobID == jobID {
+		t.Fatal("expected job of an unserved queue not to be leased")
+	}
+
+	// A worker with one slot takes exactly one job of the queue.
+	registerTestWorker(t, store, 1, queue)
+	secondJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 0, 0))
+
+	if leasedJobID := acquireTestLease(t, store); leasedJobID != jobID {
+		t.Fatalf("expected job %s to be leased once a worker serves its queue, got %s", jobID, leasedJobID)
+	}
+
+	lease, err = store.AcquireJobLease(ctx, uuid.New(), DefaultLeaseDuration)
+	if err != nil && err != pgx.ErrNoRows {
+		t.Fatal(err)
+	}
+	if err == nil && lease.JobID == secondJobID {
+		t.Fatal("expected no more jobs of the queue to be leased than its workers can take")
+	}
+}
+
+func TestLeaseSkipsSaturatedQueue(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	saturatedQueue := "lease-" + uuid.NewString()
+	openQueue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 3, saturatedQueue, openQueue)
+
+	maxConcurrency := 1
+	if err := store.SetQueueConcurrency(ctx, saturatedQueue, &maxConcurrency); err != nil {
+		t.Fatal(err)
+	}
+
+	activeJobID := createLeaseTestJob(t, store, leaseTestJobSpec(saturatedQueue, 10, 0))
+	if jobID := acquireTestLease(t, store); jobID != activeJobID {
+		t.Fatalf("expected job %s to be leased, got %s", activeJobID, jobID)
+	}
+
+	createLeaseTestJob(t, store, leaseTestJobSpec(saturatedQueue, 5, 0))
+	openJobID := createLeaseTestJob(t, store, leaseTestJobSpec(openQueue, 1, 0))
+
+	if jobID := acquireTestLease(t, store); jobID != openJobID {
+		t.Fatalf("expected job %s of the open queue to be leased, got %s", openJobID, jobID)
+	}
+}
+
+func TestLeaseSkipsSaturatedConcurrencyKey(t *testing.T) {
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 3, queue)
+
+	concurrencyKey := "account-" + uuid.NewString()
+
+	activeSpec := leaseTestJobSpec(queue, 10, 0)
+	activeSpec.ConcurrencyKey = concurrencyKey
+	activeSpec.ConcurrencyLimit = 1
+	activeJobID := createLeaseTestJob(t, store, activeSpec)
+	if jobID := acquireTestLease(t, store); jobID != activeJobID {
+		t.Fatalf("expected job %s to be leased, got %s", activeJobID, jobID)
+	}
+
+	// The waiting job allows more at once, but the active job's limit of one
+	// is the strictest.
+	waitingSpec := leaseTestJobSpec(queue, 5, 0)
+	waitingSpec.ConcurrencyKey = concurrencyKey
+	waitingSpec.ConcurrencyLimit = 5
+	createLeaseTestJob(t, store, waitingSpec)
+	otherJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 1, 0))
+
+	if jobID := acquireTestLease(t, store); jobID != otherJobID {
+		t.Fatalf("expected job %s without the key to be leased, got %s", otherJobID, jobID)
+	}
+}
+
+func TestFutureJobsStayOutOfLeaseOrder(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 2, queue)
+
+	// A large block of future jobs outranks the due one. None of them may
+	// sit in the order leasing reads.
+	const futureJobs = 1000
+
+	futureJobIDs := make([]uuid.UUID, 0, futureJobs)
+
+	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		for range futureJobs {
+			jobID, err := CreateJobTx(ctx, tx, leaseTestJobSpec(queue, 10, -time.Hour))
+			if err != nil {
+				return err
+			}
+			futureJobIDs = append(futureJobIDs, jobID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		for _, jobID := range futureJobIDs {
+			_ = store.CancelJob(context.Background(), jobID)
+		}
+	})
+
+	dueJobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 0, 0))
+
+	var ordered int
+	if err := store.connectionPool.QueryRow(
+		ctx,
+		`SELECT COUNT(*) FROM jobs WHERE id = ANY($1) AND aged_priority IS NOT NULL`,
+		futureJobIDs,
+	).Scan(&ordered); err != nil {
+		t.Fatal(err)
+	}
+	if ordered != 0 {
+		t.Fatalf("expected future jobs to have no aged priority, %d have one", ordered)
+	}
+
+	if jobID := acquireTestLease(t, store); jobID != dueJobID {
+		t.Fatalf("expected due job %s to be leased, got %s", dueJobID, jobID)
+	}
+
+	// A future job that becomes due is ordered by its priority at once.
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE jobs SET next_run_at = now() WHERE id = $1`,
+		futureJobIDs[0],
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if jobID := acquireTestLease(t, store); jobID != futureJobIDs[0] {
+		t.Fatalf("expected newly due job %s to be leased, got %s", futureJobIDs[0], jobID)
+	}
+}
+
+// testLeaseHolder returns the holder of the job's lease.
+func testLeaseHolder(t *testing.T, store *Store, jobID uuid.UUID) uuid.UUID {
+	t.Helper()
+
+	var holderID uuid.UUID
+
+	if err := store.connectionPool.QueryRow(
+		context.Background(),
+		`SELECT scheduler_id FROM job_leases WHERE job_id = $1`,
+		jobID,
+	).Scan(&holderID); err != nil {
+		t.Fatal(err)
+	}
+
+	return holderID
+}
+
+func TestRenewLeaseExtendsHeldLease(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+	holderID := testLeaseHolder(t, store, jobID)
+
+	leaseExpiresAt, err := store.RenewLease(ctx, jobID, holderID, fencingToken, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(leaseExpiresAt) < 30*time.Minute {
+		t.Fatalf("expected the lease to be extended, expires at %s", leaseExpiresAt)
+	}
+}
+
+func TestRenewLeaseWithStaleFencingTokenRejected(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+	holderID := testLeaseHolder(t, store, jobID)
+
+	if _, err := store.RenewLease(ctx, jobID, holderID, fencingToken-1, time.Hour); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld, got %v", err)
+	}
+}
+
+func TestRenewExpiredLeaseRejected(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+	holderID := testLeaseHolder(t, store, jobID)
+
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE job_leases SET lease_expires_at = now() - interval '1 second' WHERE job_id = $1`,
+		jobID,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.RenewLease(ctx, jobID, holderID, fencingToken, time.Hour); !errors.Is(err, ErrLeaseNotHeld) {
+		t.Fatalf("expected ErrLeaseNotHeld, got %v", err)
+	}
+}
+
+// claimTestLease records workerID as the claimant of the job's lease.
+func claimTestLease(t *testing.T, store *Store, jobID uuid.UUID, workerID uuid.UUID) {
+	t.Helper()
+
+	if _, err := store.connectionPool.Exec(
+		context.Background(),
+		`UPDATE job_leases SET worker_id = $2 WHERE job_id = $1`,
+		jobID,
+		workerID,
+	); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecoverWorkerLeases(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	workerID := uuid.New()
+	otherWorkerID := uuid.New()
+
+	scheduledJobID := uuid.New()
+	scheduleTestJob(t, store, scheduledJobID)
+	claimTestLease(t, store, scheduledJobID, workerID)
+
+	runningJobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, runningJobID)
+	if err := store.MarkJobRunning(ctx, runningJobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+	claimTestLease(t, store, runningJobID, workerID)
+
+	otherJobID := uuid.New()
+	fencingToken = scheduleTestJob(t, store, otherJobID)
+	if err := store.MarkJobRunning(ctx, otherJobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+	claimTestLease(t, store, otherJobID, otherWorkerID)
+
+	recovered, err := store.RecoverWorkerLeases(ctx, workerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recovered) != 2 || !slices.Contains(recovered, scheduledJobID) || !slices.Contains(recovered, runningJobID) {
+		t.Fatalf("expected jobs %s and %s to be recovered, got %v", scheduledJobID, runningJobID, recovered)
+	}
+
+	assertJobState(t, store, scheduledJobID, JobPending)
+	assertJobState(t, store, runningJobID, JobPending)
+	assertJobState(t, store, otherJobID, JobRunning)
+
+	attempts, err := store.ListJobAttempts(ctx, runningJobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Outcome == nil || *attempts[0].Outcome != AttemptLeaseExpired {
+		t.Fatalf("expected one %s attempt, got %+v", AttemptLeaseExpired, attempts)
+	}
+
+	var otherLeases int
+	if err := store.connectionPool.QueryRow(
+		ctx,
+		`SELECT COUNT(*) FROM job_leases WHERE job_id = $1 AND worker_id = $2`,
+		otherJobID,
+		otherWorkerID,
+	).Scan(&otherLeases); err != nil {
+		t.Fatal(err)
+	}
+	if otherLeases != 1 {
+		t.Fatal("expected the other worker's lease to be left alone")
+	}
+}
+
+func TestDelayedJobIsLeasedOnceDue(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	queue := "lease-" + uuid.NewString()
+	registerTestWorker(t, store, 1, queue)
+
+	jobID := createLeaseTestJob(t, store, leaseTestJobSpec(queue, 0, -time.Hour))
+
+	lease, err := store.AcquireJobLease(ctx, uuid.New(), DefaultLeaseDuration)
+	if err != nil && err != pgx.ErrNoRows {
+		t.Fatal(err)
+	}
+	if err == nil && lease.JobID == jobID {
+		t.Fatal("expected a job that is not due yet not to be leased")
+	}
+	assertJobState(t, store, jobID, JobPending)
+
+	// Time passing is simulated by moving the job's due time to now.
+	if _, err := store.connectionPool.Exec(
+		ctx,
+		`UPDATE jobs SET next_run_at = now() WHERE id = $1`,
+		jobID,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if leasedJobID := acquireTestLease(t, store); leasedJobID != jobID {
+		t.Fatalf("expected job %s to be leased once due, got %s", jobID, leasedJobID)
+	}
+}
@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultJobLogMaxBytes is how much log output a job may keep across all
+	// of its attempts when no limit is configured.
+	DefaultJobLogMaxBytes = 1 << 20
+
+	// DefaultJobLogRetention is how long log output is kept when no
+	// retention is configured.
+	DefaultJobLogRetention = 7 * 24 * time.Hour
+)
+
+// jobLogTruncatedNotice ends the log of a job that reached its limit.
+const jobLogTruncatedNotice = "[log truncated: job exceeded %d bytes of logs]\n"
+
+// JobLogChunk is one or more newline-terminated lines written by a job
+// attempt. Chunk IDs increase in the order chunks were written.
+type JobLogChunk struct {
+	ID        int64
+	JobID     uuid.UUID
+	Attempt   int
+	Content   string
+	CreatedAt time.Time
+}
+
+// JobLogFilter selects the chunks of a job following AfterID. A zero Attempt
+// selects every attempt.
+type JobLogFilter struct {
+	JobID   uuid.UUID
+	Attempt int
+	AfterID int64
+	Limit   int
+}
+
+// AppendJobLog appends content to the log of a job attempt, to be kept until
+// expiresAt. Once the job's logs would exceed maxBytes, content is cut at
+// the last whole line that fits, a truncation notice is appended, and later
+// chunks are dropped. It reports whether the log is truncated. A job's log
+// is expected to have a single writer at a time.
+func (s *Store) AppendJobLog(
+	ctx context.Context,
+	jobID uuid.UUID,
+	attempt int,
+	content string,
+	maxBytes int,
+	expiresAt time.Time,
+) (bool, error) {
+	var truncated bool
+
+	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		var used int64
+
+		if err := transaction.QueryRow(
+			ctx,
+			`SELECT COALESCE(SUM(size_bytes), 0)::bigint FROM job_logs WHERE job_id = $1`,
+			jobID,
+		).Scan(&used); err != nil {
+			return err
+		}
+
+		if used >= int64(maxBytes) {
+			truncated = true
+			return nil
+		}
+
+		if used+int64(len(content)) > int64(maxBytes) {
+			fits := content[:int64(maxBytes)-used]
+			content = fits[:strings.LastIndexByte(fits, '\n')+1] +
+				fmt.Sprintf(jobLogTruncatedNotice, maxBytes)
+			truncated = true
+		}
+
+		_, err := transaction.Exec(
+			ctx,
+			`
+			INSERT INTO job_logs (job_id, attempt, content, size_bytes, expires_at)
+			VALUES ($1, $2, $3, $4, $5)
+			`,
+			jobID,
+			attempt,
+			content,
+			len(content),
+			expiresAt,
+		)
+
+		return err
+	})
+
+	return truncated, err
+}
+
+// ListJobLogs returns the log chunks selected by filter, oldest first.
+func (s *Store) ListJobLogs(
+	ctx context.Context,
+	filter JobLogFilter,
+) ([]JobLogChunk, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT id, job_id, attempt, content, created_at
+		FROM job_logs
+		WHERE job_id = $1
+		  AND ($2 = 0 OR attempt = $2)
+		  AND id > $3
+		  AND expires_at > now()
+		ORDER BY id
+		LIMIT $4
+		`,
+		filter.JobID,
+		filter.Attempt,
+		filter.AfterID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []JobLogChunk
+
+	for rows.Next() {
+		var chunk JobLogChunk
+
+		if err := rows.Scan(
+			&chunk.ID,
+			&chunk.JobID,
+			&chunk.Attempt,
+			&chunk.Content,
+			&chunk.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// PurgeExpiredJobLogs deletes log chunks whose retention window ended before
+// now and reports how many were removed.
+func (s *Store) PurgeExpiredJobLogs(
+	ctx context.Context,
+	now time.Time,
+) (int64, error) {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`DELETE FROM job_logs WHERE expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestJobLogIsTruncatedAtLimit(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	truncated, err := store.AppendJobLog(ctx, jobID, 1, "first\n", 16, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if truncated {
+		t.Fatal("expected a log within the limit not to be truncated")
+	}
+
+	truncated, err = store.AppendJobLog(ctx, jobID, 2, "second\nthird\n", 16, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated {
+		t.Fatal("expected a log over the limit to be truncated")
+	}
+
+	truncated, err = store.AppendJobLog(ctx, jobID, 2, "fourth\n", 16, expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated {
+		t.Fatal("expected a truncated log to stay truncated")
+	}
+
+	chunks, err := store.ListJobLogs(ctx, JobLogFilter{JobID: jobID, Attempt: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("expected 1 chunk for attempt 2, got %d", len(chunks))
+	}
+	if !strings.HasPrefix(chunks[0].Content, "second\n[log truncated") {
+		t.Fatalf("unexpected truncated chunk: %q", chunks[0].Content)
+	}
+
+	chunks, err = store.ListJobLogs(ctx, JobLogFilter{JobID: jobID, AfterID: chunks[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 0 {
+		t.Fatalf("expected no chunks after the last one, got %d", len(chunks))
+	}
+}
+
+func TestJobLogsAreFilteredByAttempt(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, write := range []struct {
+		attempt int
+		content string
+	}{
+		{1, "one-a\n"},
+		{2, "two-a\n"},
+		{1, "one-b\n"},
+		{3, "three-a\n"},
+		{2, "two-b\n"},
+	} {
+		if _, err := store.AppendJobLog(ctx, jobID, write.attempt, write.content, DefaultJobLogMaxBytes, expiresAt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chunks, err := store.ListJobLogs(ctx, JobLogFilter{JobID: jobID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 5 {
+		t.Fatalf("expected 5 chunks across attempts, got %d", len(chunks))
+	}
+
+	chunks, err = store.ListJobLogs(ctx, JobLogFilter{JobID: jobID, Attempt: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 || chunks[0].Content != "two-a\n" || chunks[1].Content != "two-b\n" {
+		t.Fatalf("unexpected chunks for attempt 2: %+v", chunks)
+	}
+
+	chunks, err = store.ListJobLogs(ctx, JobLogFilter{JobID: jobID, Attempt: 1, AfterID: chunks[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "one-b\n" {
+		t.Fatalf("unexpected chunks for attempt 1 after the first of attempt 2: %+v", chunks)
+	}
+
+	chunks, err = store.ListJobLogs(ctx, JobLogFilter{JobID: jobID, Attempt: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 0 {
+		t.Fatalf("expected no chunks for an attempt without output, got %d", len(chunks))
+	}
+}
+
+func TestExpiredJobLogsArePurged(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	if _, err := store.CreateJob(ctx, newTestJobSpec(jobID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.AppendJobLog(ctx, jobID, 1, "expired\n", DefaultJobLogMaxBytes, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AppendJobLog(ctx, jobID, 1, "kept\n", DefaultJobLogMaxBytes, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks, err := store.ListJobLogs(ctx, JobLogFilter{JobID: jobID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || chunks[0].Content != "kept\n" {
+		t.Fatalf("expected expired output to be hidden, got %+v", chunks)
+	}
+
+	purged, err := store.PurgeExpiredJobLogs(ctx, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged < 1 {
+		t.Fatalf("expected the expired chunk to be purged, purged %d", purged)
+	}
+
+	var remaining int
+	if err := store.connectionPool.QueryRow(
+		ctx,
+		`SELECT COUNT(*) FROM job_logs WHERE job_id = $1`,
+		jobID,
+	).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 1 {
+		t.Fatalf("expected only the unexpired chunk to remain, got %d", remaining)
+	}
+}
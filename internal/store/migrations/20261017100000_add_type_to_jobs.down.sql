@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_type_state;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS type;
@@ -0,0 +1,8 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE jobs
+ALTER COLUMN type
+DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_type_state ON jobs (type, state);
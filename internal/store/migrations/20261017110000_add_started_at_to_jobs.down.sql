@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_running_started_at;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS started_at;
@@ -0,0 +1,6 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS started_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_running_started_at ON jobs (started_at)
+WHERE
+  state = 'RUNNING';
@@ -0,0 +1,4 @@
+ALTER TABLE job_leases
+DROP COLUMN IF EXISTS fencing_token;
+
+DROP SEQUENCE IF EXISTS job_lease_fencing_token_seq;
@@ -0,0 +1,6 @@
+CREATE SEQUENCE IF NOT EXISTS job_lease_fencing_token_seq;
+
+ALTER TABLE job_leases
+ADD COLUMN IF NOT EXISTS fencing_token BIGINT NOT NULL DEFAULT nextval('job_lease_fencing_token_seq');
+
+ALTER SEQUENCE job_lease_fencing_token_seq OWNED BY job_leases.fencing_token;
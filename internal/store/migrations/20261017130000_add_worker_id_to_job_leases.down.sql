@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_job_leases_worker_id;
+
+ALTER TABLE job_leases
+DROP COLUMN IF EXISTS worker_id;
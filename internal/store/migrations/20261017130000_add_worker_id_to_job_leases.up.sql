@@ -0,0 +1,6 @@
+ALTER TABLE job_leases
+ADD COLUMN IF NOT EXISTS worker_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_job_leases_worker_id ON job_leases (worker_id)
+WHERE
+  worker_id IS NOT NULL;
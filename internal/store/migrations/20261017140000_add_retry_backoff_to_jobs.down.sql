@@ -0,0 +1,39 @@
+ALTER TABLE jobs ADD CONSTRAINT terminal_state_no_retry CHECK (
+  NOT (
+    state IN ('COMPLETED', 'CANCELLED')
+    AND current_attempt > 0
+  )
+) NOT VALID;
+
+DROP INDEX IF EXISTS idx_jobs_pending_next_run_at;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS next_run_at,
+DROP COLUMN IF EXISTS retry_jitter,
+DROP COLUMN IF EXISTS retry_max_delay_seconds,
+DROP COLUMN IF EXISTS retry_backoff_multiplier,
+DROP COLUMN IF EXISTS retry_initial_delay_seconds;
+
+-- Restore retryable as NOT NULL DEFAULT false. Rows that finished without a
+-- retry decision are backfilled with false, which retryable_only_on_failed
+-- forbids on non-FAILED rows, so the constraint is re-added NOT VALID like
+-- terminal_state_no_retry above: it still applies to every new write.
+ALTER TABLE jobs
+DROP CONSTRAINT IF EXISTS retryable_only_on_failed;
+
+UPDATE jobs
+SET
+  retryable = FALSE
+WHERE
+  retryable IS NULL;
+
+ALTER TABLE jobs
+ALTER COLUMN retryable
+SET DEFAULT false,
+ALTER COLUMN retryable
+SET NOT NULL;
+
+ALTER TABLE jobs ADD CONSTRAINT retryable_only_on_failed CHECK (
+  retryable IS NULL
+  OR state = 'FAILED'
+) NOT VALID;
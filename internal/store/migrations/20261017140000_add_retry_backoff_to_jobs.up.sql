@@ -0,0 +1,32 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS retry_initial_delay_seconds INTEGER NOT NULL DEFAULT 1 CHECK (retry_initial_delay_seconds >= 0),
+ADD COLUMN IF NOT EXISTS retry_backoff_multiplier DOUBLE PRECISION NOT NULL DEFAULT 2 CHECK (retry_backoff_multiplier >= 1),
+ADD COLUMN IF NOT EXISTS retry_max_delay_seconds INTEGER NOT NULL DEFAULT 300 CHECK (
+  retry_max_delay_seconds >= retry_initial_delay_seconds
+),
+ADD COLUMN IF NOT EXISTS retry_jitter DOUBLE PRECISION NOT NULL DEFAULT 0.2 CHECK (
+  retry_jitter >= 0
+  AND retry_jitter <= 1
+),
+ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMPTZ NOT NULL DEFAULT now ();
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_next_run_at ON jobs (next_run_at)
+WHERE
+  state = 'PENDING';
+
+-- Retries move a RUNNING job straight back to PENDING, so a job may complete
+-- after earlier attempts failed, and retryable is only ever set on FAILED jobs.
+ALTER TABLE jobs
+DROP CONSTRAINT IF EXISTS terminal_state_no_retry;
+
+ALTER TABLE jobs
+ALTER COLUMN retryable
+DROP NOT NULL,
+ALTER COLUMN retryable
+DROP DEFAULT;
+
+UPDATE jobs
+SET
+  retryable = NULL
+WHERE
+  state <> 'FAILED';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_jobs_pending_next_run_at;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_next_run_at ON jobs (next_run_at)
+WHERE
+  state = 'PENDING';
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS run_at;
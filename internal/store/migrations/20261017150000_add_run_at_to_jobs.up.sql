@@ -0,0 +1,10 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS run_at TIMESTAMPTZ;
+
+-- Leasing walks PENDING jobs in next_run_at order and stops at the first one
+-- that is not due yet, so future jobs are never scanned.
+DROP INDEX IF EXISTS idx_jobs_pending_next_run_at;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_next_run_at ON jobs (next_run_at, created_at)
+WHERE
+  state = 'PENDING';
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_jobs_schedule_id;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS schedule_id;
+
+DROP INDEX IF EXISTS idx_schedules_due;
+
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,29 @@
+CREATE TABLE
+  schedules (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    cron_expression TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    catch_up_policy TEXT NOT NULL CHECK (catch_up_policy IN ('SKIP', 'RUN_ONCE', 'RUN_ALL')),
+    job_type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    max_attempts INTEGER NOT NULL CHECK (max_attempts >= 1),
+    timeout_seconds INTEGER NOT NULL CHECK (timeout_seconds > 0),
+    paused BOOLEAN NOT NULL DEFAULT false,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+CREATE INDEX idx_schedules_due ON schedules (next_run_at)
+WHERE
+  NOT paused;
+
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS schedule_id UUID REFERENCES schedules (id) ON DELETE SET NULL;
+
+-- At most one job per schedule tick, even if two schedulers race.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_schedule_id ON jobs (schedule_id, run_at)
+WHERE
+  schedule_id IS NOT NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_priority ON jobs (priority DESC, next_run_at)
+WHERE
+  state = 'PENDING';
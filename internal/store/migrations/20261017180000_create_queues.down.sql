@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_jobs_active_queue;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS queue;
+
+DROP TABLE IF EXISTS queues;
@@ -0,0 +1,16 @@
+-- A queue without a row here has no concurrency limit.
+CREATE TABLE
+  queues (
+    name TEXT PRIMARY KEY,
+    max_concurrency INTEGER CHECK (max_concurrency > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS queue TEXT NOT NULL DEFAULT 'default';
+
+-- Counting a queue's SCHEDULED and RUNNING jobs happens on every lease.
+CREATE INDEX IF NOT EXISTS idx_jobs_active_queue ON jobs (queue)
+WHERE
+  state IN ('SCHEDULED', 'RUNNING');
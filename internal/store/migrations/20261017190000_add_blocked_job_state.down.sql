@@ -0,0 +1,8 @@
+-- PostgreSQL cannot drop a value from an enum type. BLOCKED jobs are
+-- cancelled so that nothing depends on the value anymore.
+UPDATE jobs
+SET
+  state = 'CANCELLED',
+  cancelled_at = now ()
+WHERE
+  state = 'BLOCKED';
@@ -0,0 +1,3 @@
+-- Kept in its own migration: a new enum value cannot be used in the
+-- transaction that adds it.
+ALTER TYPE job_state ADD VALUE IF NOT EXISTS 'BLOCKED' BEFORE 'PENDING';
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_job_dependencies_depends_on;
+
+DROP TABLE IF EXISTS job_dependencies;
+
+DROP INDEX IF EXISTS idx_jobs_workflow_id;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS workflow_id;
+
+DROP TABLE IF EXISTS workflows;
@@ -0,0 +1,25 @@
+CREATE TABLE
+  workflows (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    failure_policy TEXT NOT NULL CHECK (failure_policy IN ('FAIL', 'CANCEL')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS workflow_id UUID REFERENCES workflows (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_workflow_id ON jobs (workflow_id)
+WHERE
+  workflow_id IS NOT NULL;
+
+CREATE TABLE
+  job_dependencies (
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    depends_on_job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    PRIMARY KEY (job_id, depends_on_job_id),
+    CHECK (job_id <> depends_on_job_id)
+  );
+
+-- Resolving dependents looks edges up by parent.
+CREATE INDEX idx_job_dependencies_depends_on ON job_dependencies (depends_on_job_id);
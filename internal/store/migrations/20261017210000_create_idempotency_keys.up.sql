@@ -0,0 +1,10 @@
+CREATE TABLE
+  idempotency_keys (
+    key TEXT PRIMARY KEY,
+    request_hash TEXT NOT NULL,
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    expires_at TIMESTAMPTZ NOT NULL
+  );
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,9 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS unique_key TEXT;
+
+-- At most one unfinished job per unique key. Finished jobs keep their key
+-- for reference but no longer block new ones.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key ON jobs (unique_key)
+WHERE
+  unique_key IS NOT NULL
+  AND state NOT IN ('COMPLETED', 'FAILED', 'CANCELLED');
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_jobs_active_concurrency_key;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS concurrency_limit,
+DROP COLUMN IF EXISTS concurrency_key;
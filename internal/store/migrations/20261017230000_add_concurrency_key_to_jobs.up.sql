@@ -0,0 +1,9 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS concurrency_key TEXT,
+ADD COLUMN IF NOT EXISTS concurrency_limit INTEGER NOT NULL DEFAULT 1 CHECK (concurrency_limit > 0);
+
+-- Counting a key's SCHEDULED and RUNNING jobs happens on every lease.
+CREATE INDEX IF NOT EXISTS idx_jobs_active_concurrency_key ON jobs (concurrency_key)
+WHERE
+  concurrency_key IS NOT NULL
+  AND state IN ('SCHEDULED', 'RUNNING');
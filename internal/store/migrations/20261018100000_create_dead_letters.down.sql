@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_dead_letters_created_at;
+
+DROP TABLE IF EXISTS dead_letters;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS replay_of_job_id,
+DROP COLUMN IF EXISTS error_history;
@@ -0,0 +1,16 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS error_history JSONB NOT NULL DEFAULT '[]',
+ADD COLUMN IF NOT EXISTS replay_of_job_id UUID REFERENCES jobs (id) ON DELETE SET NULL;
+
+-- A dead letter points at a job that failed for good. The job itself stays
+-- FAILED; replaying creates a new job whose replay_of_job_id links back.
+CREATE TABLE
+  dead_letters (
+    id UUID PRIMARY KEY,
+    job_id UUID NOT NULL UNIQUE REFERENCES jobs (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    replayed_job_id UUID REFERENCES jobs (id) ON DELETE SET NULL,
+    replayed_at TIMESTAMPTZ
+  );
+
+CREATE INDEX idx_dead_letters_created_at ON dead_letters (created_at);
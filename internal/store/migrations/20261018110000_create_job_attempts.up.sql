@@ -0,0 +1,16 @@
+-- One row per execution attempt. The row is opened when the job starts
+-- running and closed with its outcome when the attempt ends.
+CREATE TABLE
+  job_attempts (
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    worker_id UUID,
+    scheduler_id UUID NOT NULL,
+    fencing_token BIGINT NOT NULL,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    finished_at TIMESTAMPTZ,
+    outcome TEXT,
+    error TEXT,
+    retryable BOOLEAN,
+    PRIMARY KEY (job_id, attempt)
+  );
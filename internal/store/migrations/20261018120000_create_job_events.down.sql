@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_job_events_job_id;
+
+DROP TABLE IF EXISTS job_events;
@@ -0,0 +1,14 @@
+-- Append-only audit log of job state transitions. from_state is NULL for the
+-- event recording a job's creation.
+CREATE TABLE
+  job_events (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    from_state TEXT,
+    to_state TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+CREATE INDEX idx_job_events_job_id ON job_events (job_id, id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_job_events_txid;
+
+ALTER TABLE job_events
+DROP COLUMN IF EXISTS txid;
@@ -0,0 +1,7 @@
+-- The id of the writing transaction orders the change feed. Every event of a
+-- transaction older than the oldest running one is final, so readers that
+-- stay below that horizon never skip an event that commits late.
+ALTER TABLE job_events
+ADD COLUMN IF NOT EXISTS txid BIGINT NOT NULL DEFAULT txid_current ();
+
+CREATE INDEX idx_job_events_txid ON job_events (txid, id);
@@ -0,0 +1,37 @@
+-- A webhook receives job events matching its filters. A NULL filter matches
+-- everything.
+CREATE TABLE
+  webhooks (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    job_type TEXT,
+    queue TEXT,
+    states TEXT[],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+-- Outbox of webhook deliveries, written in the transaction that records the
+-- job event so that no event is lost.
+CREATE TABLE
+  webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id BIGINT NOT NULL REFERENCES job_events (id) ON DELETE CASCADE,
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    payload JSONB NOT NULL,
+    state TEXT NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now ()
+  );
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+WHERE
+  state = 'PENDING';
+
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at);
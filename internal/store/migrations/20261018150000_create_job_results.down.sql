@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_job_results_expires_at;
+
+DROP TABLE IF EXISTS job_results;
@@ -0,0 +1,10 @@
+CREATE TABLE
+  job_results (
+    job_id UUID PRIMARY KEY REFERENCES jobs (id) ON DELETE CASCADE,
+    result JSONB NOT NULL,
+    size_bytes INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    expires_at TIMESTAMPTZ NOT NULL
+  );
+
+CREATE INDEX idx_job_results_expires_at ON job_results (expires_at);
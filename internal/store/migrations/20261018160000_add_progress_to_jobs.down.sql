@@ -0,0 +1,5 @@
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS progress_updated_at,
+DROP COLUMN IF EXISTS progress_message,
+DROP COLUMN IF EXISTS progress_stage,
+DROP COLUMN IF EXISTS progress_percent;
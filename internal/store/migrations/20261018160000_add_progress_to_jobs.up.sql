@@ -0,0 +1,5 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS progress_percent INTEGER CHECK (progress_percent BETWEEN 0 AND 100),
+ADD COLUMN IF NOT EXISTS progress_stage TEXT,
+ADD COLUMN IF NOT EXISTS progress_message TEXT,
+ADD COLUMN IF NOT EXISTS progress_updated_at TIMESTAMPTZ;
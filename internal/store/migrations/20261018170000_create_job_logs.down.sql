@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_job_logs_expires_at;
+
+DROP INDEX IF EXISTS idx_job_logs_job_id;
+
+DROP TABLE IF EXISTS job_logs;
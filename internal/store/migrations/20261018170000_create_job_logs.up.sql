@@ -0,0 +1,16 @@
+-- Log lines written by a job's handler, in chunks of one or more
+-- newline-terminated lines per row.
+CREATE TABLE
+  job_logs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL REFERENCES jobs (id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now (),
+    expires_at TIMESTAMPTZ NOT NULL
+  );
+
+CREATE INDEX idx_job_logs_job_id ON job_logs (job_id, attempt, id);
+
+CREATE INDEX idx_job_logs_expires_at ON job_logs (expires_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_jobs_pending_aged_priority;
+
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS aged_priority;
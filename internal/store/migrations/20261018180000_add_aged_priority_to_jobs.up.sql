@@ -0,0 +1,17 @@
+-- A due job's priority raised by one for every minute it has waited. The
+-- scheduler advances it in steps so that leasing can read PENDING jobs in
+-- index order instead of sorting them all by a computed expression.
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS aged_priority INTEGER;
+
+UPDATE jobs
+SET
+  aged_priority = priority;
+
+ALTER TABLE jobs
+ALTER COLUMN aged_priority
+SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_aged_priority ON jobs (aged_priority DESC, next_run_at, created_at)
+WHERE
+  state = 'PENDING';
@@ -0,0 +1,4 @@
+-- The queues a worker takes jobs from; NULL means every queue. Schedulers
+-- only lease jobs of queues that a live worker with free capacity serves.
+ALTER TABLE workers
+ADD COLUMN IF NOT EXISTS queues TEXT[];
@@ -0,0 +1,31 @@
+ALTER TABLE jobs
+ADD COLUMN IF NOT EXISTS error_history JSONB NOT NULL DEFAULT '[]';
+
+UPDATE jobs j
+SET
+  error_history = a.history
+FROM
+  (
+    SELECT
+      job_id,
+      jsonb_agg(
+        jsonb_build_object(
+          'attempt',
+          attempt,
+          'error',
+          error,
+          'failed_at',
+          finished_at
+        )
+        ORDER BY
+          attempt
+      ) AS history
+    FROM
+      job_attempts
+    WHERE
+      error IS NOT NULL
+    GROUP BY
+      job_id
+  ) a
+WHERE
+  a.job_id = j.id;
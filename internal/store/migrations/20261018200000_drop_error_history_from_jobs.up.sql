@@ -0,0 +1,4 @@
+-- Failed attempts are recorded in job_attempts, which is the only history
+-- of a job's errors.
+ALTER TABLE jobs
+DROP COLUMN IF EXISTS error_history;
@@ -0,0 +1,4 @@
+-- Finished deliveries are purged by age once their retention ends.
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_finished ON webhook_deliveries (updated_at)
+WHERE
+  state <> 'PENDING';
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_jobs_pending_not_aged;
+
+DROP INDEX IF EXISTS idx_jobs_pending_aged_priority;
+
+UPDATE jobs
+SET
+  aged_priority = priority
+WHERE
+  aged_priority IS NULL;
+
+ALTER TABLE jobs
+ALTER COLUMN aged_priority
+SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_aged_priority ON jobs (aged_priority DESC, next_run_at, created_at)
+WHERE
+  state = 'PENDING';
@@ -0,0 +1,25 @@
+-- A PENDING job has no aged priority until it is due, which keeps future
+-- jobs out of the index leasing reads in priority order. Leasing gives due
+-- jobs their priority through the second index.
+ALTER TABLE jobs
+ALTER COLUMN aged_priority
+DROP NOT NULL;
+
+UPDATE jobs
+SET
+  aged_priority = NULL
+WHERE
+  state = 'PENDING'
+  AND next_run_at > now();
+
+DROP INDEX IF EXISTS idx_jobs_pending_aged_priority;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_aged_priority ON jobs (aged_priority DESC, next_run_at, created_at)
+WHERE
+  state = 'PENDING'
+  AND aged_priority IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_jobs_pending_not_aged ON jobs (next_run_at)
+WHERE
+  state = 'PENDING'
+  AND aged_priority IS NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys
+DROP COLUMN IF EXISTS existing_job,
+DROP COLUMN IF EXISTS job_state;
@@ -0,0 +1,6 @@
+-- The outcome of the original request, which a replay reports again: the
+-- job's state then and whether the request resolved to an existing unique
+-- job instead of creating one.
+ALTER TABLE idempotency_keys
+ADD COLUMN IF NOT EXISTS job_state TEXT NOT NULL DEFAULT 'PENDING',
+ADD COLUMN IF NOT EXISTS existing_job BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrInvalidJobProgress = errors.New("job progress percent must be between 0 and 100")
+
+// JobProgress is the latest progress reported by a RUNNING job's current
+// attempt. Stage and Message are optional.
+type JobProgress struct {
+	Percent   int
+	Stage     *string
+	Message   *string
+	UpdatedAt time.Time
+}
+
+// ReportJobProgress replaces the progress of a RUNNING job and, because a
+// report proves the holder is alive, extends its lease to at least extension
+// from now; a lease that already runs longer is left alone. Only the holder
+// of fencingToken may report. It returns the lease expiry.
+func (s *Store) ReportJobProgress(
+	ctx context.Context,
+	jobID uuid.UUID,
+	fencingToken int64,
+	progress JobProgress,
+	extension time.Duration,
+) (time.Time, error) {
+	if progress.Percent < 0 || progress.Percent > 100 {
+		return time.Time{}, ErrInvalidJobProgress
+	}
+
+	var leaseExpiresAt time.Time
+
+	err := s.WithTransaction(ctx, func(transaction pgx.Tx) error {
+		if err := checkFencingToken(ctx, transaction, jobID, fencingToken); err != nil {
+			return err
+		}
+
+		err := transaction.QueryRow(
+			ctx,
+			`
+			UPDATE job_leases
+			SET lease_expires_at = GREATEST(
+				lease_expires_at,
+				now() + $2 * interval '1 millisecond'
+			)
+			WHERE job_id = $1
+			RETURNING lease_expires_at
+			`,
+			jobID,
+			extension.Milliseconds(),
+		).Scan(&leaseExpiresAt)
+		if err != nil {
+			return err
+		}
+
+		commandTag, err := transaction.Exec(
+			ctx,
+			`
+			UPDATE jobs
+			SET progress_percent = $2,
+				progress_stage = $3,
+				progress_message = $4,
+				progress_updated_at = now()
+			WHERE id = $1
+			  AND state = 'RUNNING'
+			`,
+			jobID,
+			progress.Percent,
+			progress.Stage,
+			progress.Message,
+		)
+		if err != nil {
+			return err
+		}
+
+		if commandTag.RowsAffected() != 1 {
+			return ErrInvalidStateTransition
+		}
+
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return leaseExpiresAt, nil
+}
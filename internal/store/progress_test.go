@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestProgressIsReportedByLeaseHolder(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	stage := "rendering"
+	progress := JobProgress{Percent: 40, Stage: &stage}
+
+	if _, err := store.ReportJobProgress(ctx, jobID, fencingToken+1, progress, time.Minute); !errors.Is(err, ErrStaleFencingToken) {
+		t.Fatalf("expected stale fencing token, got %v", err)
+	}
+
+	progress.Percent = 101
+	if _, err := store.ReportJobProgress(ctx, jobID, fencingToken, progress, time.Minute); !errors.Is(err, ErrInvalidJobProgress) {
+		t.Fatalf("expected invalid progress, got %v", err)
+	}
+
+	progress.Percent = 40
+	leaseExpiresAt, err := store.ReportJobProgress(ctx, jobID, fencingToken, progress, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Until(leaseExpiresAt) < 30*time.Minute {
+		t.Fatalf("expected the lease to be extended, expires at %s", leaseExpiresAt)
+	}
+
+	unchangedExpiresAt, err := store.ReportJobProgress(ctx, jobID, fencingToken, progress, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unchangedExpiresAt.Equal(leaseExpiresAt) {
+		t.Fatalf("expected a short extension to keep the lease at %s, got %s", leaseExpiresAt, unchangedExpiresAt)
+	}
+
+	job, err := store.GetJobByID(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Progress == nil || job.Progress.Percent != 40 || job.Progress.Stage == nil || *job.Progress.Stage != stage {
+		t.Fatalf("unexpected progress: %+v", job.Progress)
+	}
+}
@@ -0,0 +1,155 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultQueue is the queue of jobs created without one.
+const DefaultQueue = "default"
+
+// Queue reports a queue's concurrency limit and current load. A nil
+// MaxConcurrency means the queue is unlimited. Active counts SCHEDULED and
+// RUNNING jobs, which are the ones occupying a slot.
+type Queue struct {
+	Name           string
+	MaxConcurrency *int
+	Pending        int
+	Active         int
+	UpdatedAt      *time.Time
+}
+
+// ListQueues returns every configured queue and every queue that currently
+// has unfinished jobs.
+func (s *Store) ListQueues(ctx context.Context) ([]Queue, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT
+			n.name,
+			q.max_concurrency,
+			COALESCE(c.pending, 0),
+			COALESCE(c.active, 0),
+			q.updated_at
+		FROM (
+			SELECT name FROM queues
+			UNION
+			SELECT DISTINCT queue FROM jobs
+			WHERE state IN ('PENDING', 'SCHEDULED', 'RUNNING')
+		) n
+		LEFT JOIN queues q ON q.name = n.name
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE state = 'PENDING') AS pending,
+				COUNT(*) FILTER (WHERE state IN ('SCHEDULED', 'RUNNING')) AS active
+			FROM jobs
+			WHERE queue = n.name
+			  AND state IN ('PENDING', 'SCHEDULED', 'RUNNING')
+		) c ON true
+		ORDER BY n.name
+		`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queues []Queue
+
+	for rows.Next() {
+		var queue Queue
+
+		if err := rows.Scan(
+			&queue.Name,
+			&queue.MaxConcurrency,
+			&queue.Pending,
+			&queue.Active,
+			&queue.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		queues = append(queues, queue)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return queues, nil
+}
+
+// SetQueueConcurrency sets the maximum number of SCHEDULED and RUNNING jobs
+// of a queue. A nil maxConcurrency removes the limit.
+func (s *Store) SetQueueConcurrency(
+	ctx context.Context,
+	name string,
+	maxConcurrency *int,
+) error {
+	_, err := s.connectionPool.Exec(
+		ctx,
+		`
+		INSERT INTO queues (name, max_concurrency)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE
+		SET max_concurrency = EXCLUDED.max_concurrency,
+			updated_at = now()
+		`,
+		name,
+		maxConcurrency,
+	)
+
+	return err
+}
+
+// reserveQueueSlot reports whether the queue has a free slot. Schedulers
+// checking the same queue serialize on a transaction-scoped advisory lock; a
+// scheduler that cannot take the lock immediately treats the queue as
+// saturated rather than wait, so the limit holds even when they race.
+func reserveQueueSlot(
+	ctx context.Context,
+	tx pgx.Tx,
+	queue string,
+) (bool, error) {
+	var maxConcurrency *int
+
+	err := tx.QueryRow(
+		ctx,
+		`SELECT max_concurrency FROM queues WHERE name = $1`,
+		queue,
+	).Scan(&maxConcurrency)
+	if err == pgx.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if maxConcurrency == nil {
+		return true, nil
+	}
+
+	locked, err := tryAdvisoryLock(ctx, tx, advisoryLockQueue, queue)
+	if err != nil || !locked {
+		return false, err
+	}
+
+	var active int
+
+	if err := tx.QueryRow(
+		ctx,
+		`
+		SELECT COUNT(*)
+		FROM jobs
+		WHERE queue = $1
+		  AND state IN ('SCHEDULED', 'RUNNING')
+		`,
+		queue,
+	).Scan(&active); err != nil {
+		return false, err
+	}
+
+	return active < *maxConcurrency, nil
+}
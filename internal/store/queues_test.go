@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestQueueConcurrencyLimitEnforced(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	queue := "queue-" + uuid.NewString()
+	maxConcurrency := 1
+
+	if err := store.SetQueueConcurrency(ctx, queue, &maxConcurrency); err != nil {
+		t.Fatal(err)
+	}
+
+	jobID := uuid.New()
+	spec := newTestJobSpec(jobID)
+	spec.Queue = queue
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		available, err := reserveQueueSlot(ctx, tx, queue)
+		if err != nil {
+			return err
+		}
+		if !available {
+			t.Fatal("expected a free slot")
+		}
+
+		if err := transitionJobState(ctx, tx, jobID, JobScheduled, JobPending, ""); err != nil {
+			return err
+		}
+
+		available, err = reserveQueueSlot(ctx, tx, queue)
+		if err != nil {
+			return err
+		}
+		if available {
+			t.Fatal("expected the queue to be saturated")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// DefaultJobResultMaxBytes is the largest result accepted when no limit
+	// is configured.
+	DefaultJobResultMaxBytes = 1 << 20
+
+	// DefaultJobResultRetention is how long a result is kept when no
+	// retention is configured.
+	DefaultJobResultRetention = 7 * 24 * time.Hour
+)
+
+// JobResult is the JSON output a job produced when it completed. It can be
+// read until ExpiresAt, after which it is purged.
+type JobResult struct {
+	JobID     uuid.UUID
+	Result    []byte
+	SizeBytes int
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// GetJobResult returns the result of a job, or nil when the job has no
+// result or its result expired.
+func (s *Store) GetJobResult(
+	ctx context.Context,
+	jobID uuid.UUID,
+) (*JobResult, error) {
+	var result JobResult
+
+	err := s.connectionPool.QueryRow(
+		ctx,
+		`
+		SELECT job_id, result, size_bytes, created_at, expires_at
+		FROM job_results
+		WHERE job_id = $1
+		  AND expires_at > now()
+		`,
+		jobID,
+	).Scan(
+		&result.JobID,
+		&result.Result,
+		&result.SizeBytes,
+		&result.CreatedAt,
+		&result.ExpiresAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// PurgeExpiredJobResults deletes results whose retention window ended before
+// now and reports how many were removed.
+func (s *Store) PurgeExpiredJobResults(
+	ctx context.Context,
+	now time.Time,
+) (int64, error) {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`DELETE FROM job_results WHERE expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
+
+// storeJobResult saves the result of a completing job.
+func storeJobResult(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	result []byte,
+	expiresAt time.Time,
+) error {
+	_, err := transaction.Exec(
+		ctx,
+		`
+		INSERT INTO job_results (job_id, result, size_bytes, expires_at)
+		VALUES ($1, $2, $3, $4)
+		`,
+		jobID,
+		result,
+		len(result),
+		expiresAt,
+	)
+
+	return err
+}
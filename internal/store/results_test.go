@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCompletedJobResultIsStoredUntilExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobID := uuid.New()
+	fencingToken := scheduleTestJob(t, store, jobID)
+
+	if err := store.MarkJobRunning(ctx, jobID, fencingToken); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.CompleteJob(ctx, jobID, fencingToken, &JobResult{
+		Result:    []byte(`{"rows": 42}`),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := store.GetJobResult(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || result.JobID != jobID || result.SizeBytes != len(`{"rows": 42}`) {
+		t.Fatalf("unexpected job result: %+v", result)
+	}
+
+	if _, err := store.PurgeExpiredJobResults(ctx, time.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = store.GetJobResult(ctx, jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != nil {
+		t.Fatalf("expected purged job result, got %+v", result)
+	}
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrInvalidRetryPolicy = errors.New("invalid retry policy")
+
+// RetryPolicy controls how long a failed job waits before its next attempt.
+//
+// The delay before retry n is InitialDelay * Multiplier^(n-1), capped at
+// MaxDelay. Jitter is the fraction of that delay which is randomized, so a
+// Jitter of 0.2 spreads retries over ±20% of the computed delay.
+type RetryPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxDelay     time.Duration
+	Jitter       float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	InitialDelay: time.Second,
+	Multiplier:   2,
+	MaxDelay:     5 * time.Minute,
+	Jitter:       0.2,
+}
+
+func (p RetryPolicy) Validate() error {
+	if p.InitialDelay < 0 ||
+		p.Multiplier < 1 ||
+		p.MaxDelay < p.InitialDelay ||
+		p.Jitter < 0 || p.Jitter > 1 {
+		return ErrInvalidRetryPolicy
+	}
+
+	return nil
+}
+
+// Backoff returns the delay before the given retry, where retry 1 is the
+// first re-execution after the initial attempt failed.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(p.Multiplier, float64(retry-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(math.Min(delay, float64(p.MaxDelay)))
+}
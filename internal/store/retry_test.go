@@ -0,0 +1,63 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoffGrowsAndIsCapped(t *testing.T) {
+	policy := RetryPolicy{
+		InitialDelay: time.Second,
+		Multiplier:   2,
+		MaxDelay:     10 * time.Second,
+	}
+
+	expected := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+
+	for i, want := range expected {
+		if got := policy.Backoff(i + 1); got != want {
+			t.Fatalf("retry %d: expected %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestRetryPolicyJitterStaysInBounds(t *testing.T) {
+	policy := RetryPolicy{
+		InitialDelay: 10 * time.Second,
+		Multiplier:   1,
+		MaxDelay:     time.Minute,
+		Jitter:       0.5,
+	}
+
+	for range 1000 {
+		delay := policy.Backoff(1)
+		if delay < 5*time.Second || delay > 15*time.Second {
+			t.Fatalf("delay %s outside of jitter bounds", delay)
+		}
+	}
+}
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := DefaultRetryPolicy.Validate(); err != nil {
+		t.Fatalf("default policy rejected: %v", err)
+	}
+
+	invalid := []RetryPolicy{
+		{InitialDelay: time.Second, Multiplier: 0.5, MaxDelay: time.Minute},
+		{InitialDelay: time.Minute, Multiplier: 2, MaxDelay: time.Second},
+		{InitialDelay: time.Second, Multiplier: 2, MaxDelay: time.Minute, Jitter: 1.5},
+	}
+
+	for _, policy := range invalid {
+		if err := policy.Validate(); err != ErrInvalidRetryPolicy {
+			t.Fatalf("expected ErrInvalidRetryPolicy for %+v, got %v", policy, err)
+		}
+	}
+}
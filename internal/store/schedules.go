@@ -0,0 +1,374 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	CatchUpSkip    = "SKIP"
+	CatchUpRunOnce = "RUN_ONCE"
+	CatchUpRunAll  = "RUN_ALL"
+)
+
+var (
+	ErrScheduleNotFound  = errors.New("schedule not found")
+	ErrScheduleNameTaken = errors.New("schedule name already in use")
+)
+
+// Schedule is a recurring job definition. The scheduler turns every tick of
+// CronExpression, evaluated in Timezone, into a job built from the Job*
+// fields.
+type Schedule struct {
+	ID             uuid.UUID
+	Name           string
+	CronExpression string
+	Timezone       string
+	CatchUpPolicy  string
+	JobType        string
+	Payload        []byte
+	MaxAttempts    int
+	TimeoutSeconds int
+	Paused         bool
+	NextRunAt      time.Time
+	LastRunAt      *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+const scheduleColumns = `
+	id,
+	name,
+	cron_expression,
+	timezone,
+	catch_up_policy,
+	job_type,
+	payload,
+	max_attempts,
+	timeout_seconds,
+	paused,
+	next_run_at,
+	last_run_at,
+	created_at,
+	updated_at
+`
+
+func scanSchedule(row rowScanner) (Schedule, error) {
+	var schedule Schedule
+
+	err := row.Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.CronExpression,
+		&schedule.Timezone,
+		&schedule.CatchUpPolicy,
+		&schedule.JobType,
+		&schedule.Payload,
+		&schedule.MaxAttempts,
+		&schedule.TimeoutSeconds,
+		&schedule.Paused,
+		&schedule.NextRunAt,
+		&schedule.LastRunAt,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+
+	return schedule, err
+}
+
+func (s *Store) CreateSchedule(
+	ctx context.Context,
+	schedule Schedule,
+) error {
+	_, err := s.connectionPool.Exec(
+		ctx,
+		`
+		INSERT INTO schedules (
+			id,
+			name,
+			cron_expression,
+			timezone,
+			catch_up_policy,
+			job_type,
+			payload,
+			max_attempts,
+			timeout_seconds,
+			paused,
+			next_run_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`,
+		schedule.ID,
+		schedule.Name,
+		schedule.CronExpression,
+		schedule.Timezone,
+		schedule.CatchUpPolicy,
+		schedule.JobType,
+		schedule.Payload,
+		schedule.MaxAttempts,
+		schedule.TimeoutSeconds,
+		schedule.Paused,
+		schedule.NextRunAt,
+	)
+
+	return scheduleWriteError(err)
+}
+
+func (s *Store) GetScheduleByID(
+	ctx context.Context,
+	scheduleID uuid.UUID,
+) (*Schedule, error) {
+	row := s.connectionPool.QueryRow(
+		ctx,
+		`SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`,
+		scheduleID,
+	)
+
+	schedule, err := scanSchedule(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+func (s *Store) ListSchedules(
+	ctx context.Context,
+	limit int,
+) ([]Schedule, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		ORDER BY name
+		LIMIT $1
+		`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// UpdateSchedule replaces the definition of an existing schedule. The paused
+// flag and last run are left untouched.
+func (s *Store) UpdateSchedule(
+	ctx context.Context,
+	schedule Schedule,
+) error {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`
+		UPDATE schedules
+		SET name = $2,
+			cron_expression = $3,
+			timezone = $4,
+			catch_up_policy = $5,
+			job_type = $6,
+			payload = $7,
+			max_attempts = $8,
+			timeout_seconds = $9,
+			next_run_at = $10,
+			updated_at = now()
+		WHERE id = $1
+		`,
+		schedule.ID,
+		schedule.Name,
+		schedule.CronExpression,
+		schedule.Timezone,
+		schedule.CatchUpPolicy,
+		schedule.JobType,
+		schedule.Payload,
+		schedule.MaxAttempts,
+		schedule.TimeoutSeconds,
+		schedule.NextRunAt,
+	)
+	if err != nil {
+		return scheduleWriteError(err)
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// SetSchedulePaused pauses or resumes a schedule. Resuming requires the next
+// tick to fire, so that ticks missed while paused are never caught up.
+func (s *Store) SetSchedulePaused(
+	ctx context.Context,
+	scheduleID uuid.UUID,
+	paused bool,
+	nextRunAt *time.Time,
+) error {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`
+		UPDATE schedules
+		SET paused = $2,
+			next_run_at = COALESCE($3, next_run_at),
+			updated_at = now()
+		WHERE id = $1
+		`,
+		scheduleID,
+		paused,
+		nextRunAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+func (s *Store) DeleteSchedule(
+	ctx context.Context,
+	scheduleID uuid.UUID,
+) error {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`DELETE FROM schedules WHERE id = $1`,
+		scheduleID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// LockDueSchedules locks up to limit active schedules whose next tick is at
+// or before now. Schedules locked by another scheduler are skipped, so each
+// tick is handled by exactly one transaction.
+func (s *Store) LockDueSchedules(
+	ctx context.Context,
+	tx pgx.Tx,
+	now time.Time,
+	limit int,
+) ([]Schedule, error) {
+	rows, err := tx.Query(
+		ctx,
+		`
+		SELECT `+scheduleColumns+`
+		FROM schedules
+		WHERE NOT paused
+		  AND next_run_at <= $1
+		ORDER BY next_run_at
+		FOR UPDATE SKIP LOCKED
+		LIMIT $2
+		`,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+// PauseScheduleTx pauses a schedule locked by LockDueSchedules.
+func (s *Store) PauseScheduleTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	scheduleID uuid.UUID,
+) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		UPDATE schedules
+		SET paused = true,
+			updated_at = now()
+		WHERE id = $1
+		`,
+		scheduleID,
+	)
+
+	return err
+}
+
+func (s *Store) AdvanceSchedule(
+	ctx context.Context,
+	tx pgx.Tx,
+	scheduleID uuid.UUID,
+	nextRunAt time.Time,
+	lastRunAt *time.Time,
+) error {
+	_, err := tx.Exec(
+		ctx,
+		`
+		UPDATE schedules
+		SET next_run_at = $2,
+			last_run_at = COALESCE($3, last_run_at),
+			updated_at = now()
+		WHERE id = $1
+		`,
+		scheduleID,
+		nextRunAt,
+		lastRunAt,
+	)
+
+	return err
+}
+
+func scheduleWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrScheduleNameTaken
+	}
+
+	return err
+}
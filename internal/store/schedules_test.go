@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func newTestSchedule(name string, nextRunAt time.Time) Schedule {
+	return Schedule{
+		ID:             uuid.New(),
+		Name:           name,
+		CronExpression: "* * * * *",
+		Timezone:       "UTC",
+		CatchUpPolicy:  CatchUpSkip,
+		JobType:        "test",
+		Payload:        []byte(`{}`),
+		MaxAttempts:    3,
+		TimeoutSeconds: 30,
+		NextRunAt:      nextRunAt,
+	}
+}
+
+func TestScheduleNameIsUnique(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	name := "schedule-" + uuid.NewString()
+
+	if err := store.CreateSchedule(ctx, newTestSchedule(name, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CreateSchedule(ctx, newTestSchedule(name, time.Now())); !errors.Is(err, ErrScheduleNameTaken) {
+		t.Fatalf("expected ErrScheduleNameTaken, got %v", err)
+	}
+}
+
+func TestDueScheduleLockedByOneTransaction(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	schedule := newTestSchedule("schedule-"+uuid.NewString(), time.Now().Add(-time.Hour))
+	if err := store.CreateSchedule(ctx, schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	contains := func(schedules []Schedule) bool {
+		for _, locked := range schedules {
+			if locked.ID == schedule.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	err := store.WithTransaction(ctx, func(first pgx.Tx) error {
+		locked, err := store.LockDueSchedules(ctx, first, time.Now(), 1000)
+		if err != nil {
+			return err
+		}
+		if !contains(locked) {
+			t.Fatal("expected due schedule to be locked")
+		}
+
+		return store.WithTransaction(ctx, func(second pgx.Tx) error {
+			locked, err := store.LockDueSchedules(ctx, second, time.Now(), 1000)
+			if err != nil {
+				return err
+			}
+			if contains(locked) {
+				t.Fatal("schedule locked by two transactions")
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
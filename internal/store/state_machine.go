@@ -3,6 +3,7 @@ package store
 import "fmt"
 
 const (
+	JobBlocked   = "BLOCKED"
 	JobPending   = "PENDING"
 	JobScheduled = "SCHEDULED"
 	JobRunning   = "RUNNING"
@@ -18,6 +19,11 @@ var terminalStates = map[string]bool{
 }
 
 var allowedTransitions = map[string]map[string]bool{
+	JobBlocked: {
+		JobPending:   true,
+		JobFailed:    true,
+		JobCancelled: true,
+	},
 	JobPending: {
 		JobScheduled: true,
 		JobCancelled: true,
@@ -28,12 +34,24 @@ var allowedTransitions = map[string]map[string]bool{
 		JobCancelled: true,
 	},
 	JobRunning: {
+		JobPending:   true,
 		JobCompleted: true,
 		JobFailed:    true,
 		JobCancelled: true,
 	},
 }
 
+// IsJobState reports whether state is one of the job states.
+func IsJobState(state string) bool {
+	_, ok := allowedTransitions[state]
+	return ok || terminalStates[state]
+}
+
+// IsTerminalState reports whether a job in state never transitions again.
+func IsTerminalState(state string) bool {
+	return terminalStates[state]
+}
+
 func ValidateJobTransition(from, to string) error {
 	if terminalStates[from] {
 		return fmt.Errorf("%w: cannot transition from terminal state %s",
@@ -46,4 +64,4 @@ func ValidateJobTransition(from, to string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 func newTestStore(t *testing.T) *Store {
@@ -21,6 +23,16 @@ func newTestStore(t *testing.T) *Store {
 	return store
 }
 
+func newTestJobSpec(jobID uuid.UUID) JobSpec {
+	return JobSpec{
+		ID:             jobID,
+		Type:           "test",
+		Payload:        []byte(`{}`),
+		MaxAttempts:    3,
+		TimeoutSeconds: 30,
+	}
+}
+
 func testDatabaseURL() string {
 	url := os.Getenv("TEST_DATABASE_URL")
 	if url == "" {
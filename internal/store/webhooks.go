@@ -0,0 +1,423 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// Webhook subscribes a URL to job events. A nil JobType or Queue and empty
+// States match every job event.
+type Webhook struct {
+	ID        uuid.UUID
+	URL       string
+	Secret    string
+	JobType   *string
+	Queue     *string
+	States    []string
+	CreatedAt time.Time
+}
+
+// WebhookDelivery is one job event to be sent to one webhook. Deliveries
+// are retried until they succeed or run out of attempts.
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	WebhookID      uuid.UUID
+	EventID        int64
+	JobID          uuid.UUID
+	Payload        []byte
+	State          string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastStatusCode *int
+	LastError      *string
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// DueWebhookDelivery is a claimed delivery together with where to send it.
+type DueWebhookDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string
+}
+
+const webhookColumns = `
+	id,
+	url,
+	secret,
+	job_type,
+	queue,
+	states,
+	created_at
+`
+
+func scanWebhook(row rowScanner) (Webhook, error) {
+	var webhook Webhook
+
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.JobType,
+		&webhook.Queue,
+		&webhook.States,
+		&webhook.CreatedAt,
+	)
+
+	return webhook, err
+}
+
+const webhookDeliveryColumns = `
+	d.id,
+	d.webhook_id,
+	d.event_id,
+	d.job_id,
+	d.payload,
+	d.state,
+	d.attempts,
+	d.next_attempt_at,
+	d.last_status_code,
+	d.last_error,
+	d.delivered_at,
+	d.created_at,
+	d.updated_at
+`
+
+func scanWebhookDelivery(row rowScanner) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventID,
+		&delivery.JobID,
+		&delivery.Payload,
+		&delivery.State,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	)
+
+	return delivery, err
+}
+
+func (s *Store) CreateWebhook(
+	ctx context.Context,
+	webhook Webhook,
+) error {
+	var states []string
+	if len(webhook.States) > 0 {
+		states = webhook.States
+	}
+
+	_, err := s.connectionPool.Exec(
+		ctx,
+		`
+		INSERT INTO webhooks (id, url, secret, job_type, queue, states)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		`,
+		webhook.ID,
+		webhook.URL,
+		webhook.Secret,
+		webhook.JobType,
+		webhook.Queue,
+		states,
+	)
+
+	return err
+}
+
+func (s *Store) GetWebhookByID(
+	ctx context.Context,
+	webhookID uuid.UUID,
+) (*Webhook, error) {
+	row := s.connectionPool.QueryRow(
+		ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`,
+		webhookID,
+	)
+
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook along with its deliveries.
+func (s *Store) DeleteWebhook(
+	ctx context.Context,
+	webhookID uuid.UUID,
+) error {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`DELETE FROM webhooks WHERE id = $1`,
+		webhookID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if commandTag.RowsAffected() != 1 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook, newest first.
+func (s *Store) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	limit int,
+) ([]WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.webhook_id = $1
+		ORDER BY d.created_at DESC, d.event_id DESC
+		LIMIT $2
+		`,
+		webhookID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhookDelivery queues a delivery of the webhook to be sent
+// again right away with a fresh retry budget, whatever its current state.
+func (s *Store) RedeliverWebhookDelivery(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	deliveryID uuid.UUID,
+) (*WebhookDelivery, error) {
+	row := s.connectionPool.QueryRow(
+		ctx,
+		`
+		UPDATE webhook_deliveries d
+		SET state = 'PENDING',
+			attempts = 0,
+			next_attempt_at = now(),
+			updated_at = now()
+		WHERE d.id = $1
+		  AND d.webhook_id = $2
+		RETURNING `+webhookDeliveryColumns,
+		deliveryID,
+		webhookID,
+	)
+
+	delivery, err := scanWebhookDelivery(row)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// ClaimDueWebhookDeliveries claims up to limit pending deliveries that are
+// due. Each claimed delivery is pushed back by claimFor, so a dispatcher that
+// crashes before recording the result leaves it to be retried rather than
+// lost, and concurrent dispatchers never claim the same delivery.
+func (s *Store) ClaimDueWebhookDeliveries(
+	ctx context.Context,
+	limit int,
+	claimFor time.Duration,
+) ([]DueWebhookDelivery, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		WITH due AS (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE state = 'PENDING'
+			  AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT $1
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 millisecond',
+			updated_at = now()
+		FROM due, webhooks w
+		WHERE d.id = due.id
+		  AND w.id = d.webhook_id
+		RETURNING w.url, w.secret, `+webhookDeliveryColumns,
+		limit,
+		claimFor.Milliseconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []DueWebhookDelivery
+
+	for rows.Next() {
+		var delivery DueWebhookDelivery
+
+		delivery.WebhookDelivery, err = scanWebhookDelivery(prefixScanner{
+			row:    rows,
+			prefix: []any{&delivery.URL, &delivery.Secret},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookDeliveryAttempt stores the outcome of sending a delivery. A
+// failed attempt is retried at retryAt, or marks the delivery FAILED when
+// retryAt is nil.
+func (s *Store) RecordWebhookDeliveryAttempt(
+	ctx context.Context,
+	deliveryID uuid.UUID,
+	delivered bool,
+	statusCode *int,
+	errMessage *string,
+	retryAt *time.Time,
+) error {
+	state := WebhookDeliveryDelivered
+	if !delivered {
+		state = WebhookDeliveryFailed
+		if retryAt != nil {
+			state = WebhookDeliveryPending
+		}
+	}
+
+	_, err := s.connectionPool.Exec(
+		ctx,
+		`
+		UPDATE webhook_deliveries
+		SET state = $2,
+			attempts = attempts + 1,
+			last_status_code = $3,
+			last_error = $4,
+			next_attempt_at = COALESCE($5, next_attempt_at),
+			delivered_at = CASE WHEN $2 = 'DELIVERED' THEN now() END,
+			updated_at = now()
+		WHERE id = $1
+		  AND state = 'PENDING'
+		`,
+		deliveryID,
+		state,
+		statusCode,
+		errMessage,
+		retryAt,
+	)
+
+	return err
+}
+
+// PurgeFinishedWebhookDeliveries removes delivered and failed deliveries
+// last updated before cutoff and reports how many were removed. Pending
+// deliveries are kept however old they are.
+func (s *Store) PurgeFinishedWebhookDeliveries(
+	ctx context.Context,
+	cutoff time.Time,
+) (int64, error) {
+	commandTag, err := s.connectionPool.Exec(
+		ctx,
+		`
+		DELETE FROM webhook_deliveries
+		WHERE state <> 'PENDING'
+		  AND updated_at < $1
+		`,
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return commandTag.RowsAffected(), nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMatchingTransitionQueuesWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobType := "webhook-" + uuid.NewString()
+	webhook := Webhook{
+		ID:      uuid.New(),
+		URL:     "https://example.com/hooks",
+		Secret:  "secret",
+		JobType: &jobType,
+		States:  []string{JobCancelled},
+	}
+
+	if err := store.CreateWebhook(ctx, webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := newTestJobSpec(uuid.New())
+	spec.Type = jobType
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := store.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("expected no delivery for an unsubscribed state, got %d", len(deliveries))
+	}
+
+	if err := store.CancelJob(ctx, spec.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err = store.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+	}
+
+	delivery := deliveries[0]
+	if delivery.JobID != spec.ID || delivery.State != WebhookDeliveryPending || delivery.Attempts != 0 {
+		t.Fatalf("unexpected delivery: %+v", delivery)
+	}
+}
+
+func TestFinishedWebhookDeliveriesArePurged(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	jobType := "webhook-" + uuid.NewString()
+	webhook := Webhook{
+		ID:      uuid.New(),
+		URL:     "https://example.com/hooks",
+		Secret:  "secret",
+		JobType: &jobType,
+	}
+
+	if err := store.CreateWebhook(ctx, webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := newTestJobSpec(uuid.New())
+	spec.Type = jobType
+
+	if _, err := store.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err := store.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(deliveries))
+	}
+
+	// Pending deliveries are kept however old they are.
+	if _, err := store.PurgeFinishedWebhookDeliveries(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	errMessage := "connection refused"
+	if err := store.RecordWebhookDeliveryAttempt(ctx, deliveries[0].ID, false, nil, &errMessage, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err = store.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].State != WebhookDeliveryFailed {
+		t.Fatalf("expected the delivery to be kept and FAILED, got %+v", deliveries)
+	}
+
+	if _, err := store.PurgeFinishedWebhookDeliveries(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries, err = store.ListWebhookDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 0 {
+		t.Fatalf("expected the failed delivery to be purged, got %d", len(deliveries))
+	}
+}
@@ -30,21 +30,30 @@ func (s *Store) UpsertWorkerHeartbeat(
 	return err
 }
 
+// RegisterWorker records a worker, its capacity and the queues it takes
+// jobs from. Empty queues means every queue.
 func (s *Store) RegisterWorker(
 	ctx context.Context,
 	workerID uuid.UUID,
 	capacity int,
+	queues []string,
 ) error {
+	if len(queues) == 0 {
+		queues = nil
+	}
+
 	_, err := s.connectionPool.Exec(ctx,
 		`
-		INSERT INTO workers (id, capacity, last_heartbeat)
-		VALUES ($1, $2, now())
+		INSERT INTO workers (id, capacity, queues, last_heartbeat)
+		VALUES ($1, $2, $3, now())
 		ON CONFLICT (id) DO UPDATE
 		SET capacity = EXCLUDED.capacity,
+			queues = EXCLUDED.queues,
 			last_heartbeat = now()
 		`,
 		workerID,
 		capacity,
+		queues,
 	)
 	return err
 }
@@ -0,0 +1,405 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	WorkflowFailDependents   = "FAIL"
+	WorkflowCancelDependents = "CANCEL"
+)
+
+var (
+	ErrWorkflowCycle             = errors.New("workflow dependencies contain a cycle")
+	ErrWorkflowUnknownDependency = errors.New("workflow job depends on a job outside the workflow")
+)
+
+// Workflow groups jobs connected by dependencies. FailurePolicy decides what
+// happens to the blocked dependents of a job that fails or is cancelled.
+type Workflow struct {
+	ID            uuid.UUID
+	Name          string
+	FailurePolicy string
+	CreatedAt     time.Time
+}
+
+// WorkflowJob is a job to create as part of a workflow. A job with
+// dependencies starts BLOCKED and becomes PENDING once every job it depends
+// on has COMPLETED.
+type WorkflowJob struct {
+	Spec      JobSpec
+	DependsOn []uuid.UUID
+}
+
+// CreateWorkflow creates a workflow and all its jobs in one transaction.
+// The dependency graph must be acyclic and may only reference jobs of the
+// same workflow.
+func (s *Store) CreateWorkflow(
+	ctx context.Context,
+	workflow Workflow,
+	jobs []WorkflowJob,
+) error {
+	if err := validateWorkflowGraph(jobs); err != nil {
+		return err
+	}
+
+	return s.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(
+			ctx,
+			`
+			INSERT INTO workflows (id, name, failure_policy)
+			VALUES ($1, $2, $3)
+			`,
+			workflow.ID,
+			workflow.Name,
+			workflow.FailurePolicy,
+		); err != nil {
+			return err
+		}
+
+		for _, job := range jobs {
+			state := JobPending
+			if len(job.DependsOn) > 0 {
+				state = JobBlocked
+			}
+
+			jobID, err := createJob(ctx, tx, job.Spec, state, &workflow.ID)
+			if err != nil {
+				return err
+			}
+
+			// Dependencies reference the IDs chosen by the caller, so a
+			// workflow job cannot collapse into an existing unique job.
+			if jobID != job.Spec.ID {
+				return ErrUniqueJobExists
+			}
+		}
+
+		for _, job := range jobs {
+			for _, dependsOn := range job.DependsOn {
+				if _, err := tx.Exec(
+					ctx,
+					`
+					INSERT INTO job_dependencies (job_id, depends_on_job_id)
+					VALUES ($1, $2)
+					ON CONFLICT DO NOTHING
+					`,
+					job.Spec.ID,
+					dependsOn,
+				); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// validateWorkflowGraph rejects dependencies on unknown jobs and cycles,
+// using Kahn's algorithm: if a topological order cannot consume every job,
+// the remainder contains a cycle.
+func validateWorkflowGraph(jobs []WorkflowJob) error {
+	inDegree := make(map[uuid.UUID]int, len(jobs))
+	dependents := make(map[uuid.UUID][]uuid.UUID, len(jobs))
+
+	for _, job := range jobs {
+		inDegree[job.Spec.ID] = 0
+	}
+
+	for _, job := range jobs {
+		seen := make(map[uuid.UUID]bool, len(job.DependsOn))
+
+		for _, dependsOn := range job.DependsOn {
+			if _, ok := inDegree[dependsOn]; !ok {
+				return ErrWorkflowUnknownDependency
+			}
+			if seen[dependsOn] {
+				continue
+			}
+			seen[dependsOn] = true
+
+			inDegree[job.Spec.ID]++
+			dependents[dependsOn] = append(dependents[dependsOn], job.Spec.ID)
+		}
+	}
+
+	var ready []uuid.UUID
+	for jobID, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, jobID)
+		}
+	}
+
+	visited := 0
+	for len(ready) > 0 {
+		jobID := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		visited++
+
+		for _, dependent := range dependents[jobID] {
+			inDegree[dependent]--
+			if inDegree[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if visited != len(jobs) {
+		return ErrWorkflowCycle
+	}
+
+	return nil
+}
+
+func (s *Store) GetWorkflowByID(
+	ctx context.Context,
+	workflowID uuid.UUID,
+) (*Workflow, error) {
+	var workflow Workflow
+
+	err := s.connectionPool.QueryRow(
+		ctx,
+		`
+		SELECT id, name, failure_policy, created_at
+		FROM workflows
+		WHERE id = $1
+		`,
+		workflowID,
+	).Scan(
+		&workflow.ID,
+		&workflow.Name,
+		&workflow.FailurePolicy,
+		&workflow.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &workflow, nil
+}
+
+// ListWorkflowJobs returns the jobs of a workflow in creation order, along
+// with the jobs each of them depends on.
+func (s *Store) ListWorkflowJobs(
+	ctx context.Context,
+	workflowID uuid.UUID,
+) ([]Job, map[uuid.UUID][]uuid.UUID, error) {
+	rows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT `+jobColumns+`
+		FROM jobs j
+		LEFT JOIN job_leases l ON l.job_id = j.id
+		WHERE j.workflow_id = $1
+		ORDER BY j.created_at, j.id
+		`,
+		workflowID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var jobs []Job
+
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	dependencyRows, err := s.connectionPool.Query(
+		ctx,
+		`
+		SELECT d.job_id, d.depends_on_job_id
+		FROM job_dependencies d
+		JOIN jobs j ON j.id = d.job_id
+		WHERE j.workflow_id = $1
+		`,
+		workflowID,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer dependencyRows.Close()
+
+	dependsOn := make(map[uuid.UUID][]uuid.UUID)
+
+	for dependencyRows.Next() {
+		var jobID, parentID uuid.UUID
+
+		if err := dependencyRows.Scan(&jobID, &parentID); err != nil {
+			return nil, nil, err
+		}
+
+		dependsOn[jobID] = append(dependsOn[jobID], parentID)
+	}
+
+	if err := dependencyRows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return jobs, dependsOn, nil
+}
+
+// resolveDependents runs whenever a job reaches a terminal state. Blocked
+// dependents are unblocked once all their parents completed, or failed or
+// cancelled per their workflow's policy when a parent did not complete.
+//
+// Dependents are locked before their parents are inspected, so when two
+// parents finish concurrently the second transaction to take the lock sees
+// the first one's result and unblocks the job.
+func resolveDependents(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+	state string,
+) error {
+	rows, err := transaction.Query(
+		ctx,
+		`
+		SELECT j.id, COALESCE(w.failure_policy, $2)
+		FROM job_dependencies d
+		JOIN jobs j ON j.id = d.job_id
+		LEFT JOIN workflows w ON w.id = j.workflow_id
+		WHERE d.depends_on_job_id = $1
+		  AND j.state = 'BLOCKED'
+		ORDER BY j.id
+		FOR UPDATE OF j
+		`,
+		jobID,
+		WorkflowCancelDependents,
+	)
+	if err != nil {
+		return err
+	}
+
+	type dependent struct {
+		id            uuid.UUID
+		failurePolicy string
+	}
+
+	var dependents []dependent
+
+	for rows.Next() {
+		var d dependent
+
+		if err := rows.Scan(&d.id, &d.failurePolicy); err != nil {
+			rows.Close()
+			return err
+		}
+
+		dependents = append(dependents, d)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range dependents {
+		if state == JobCompleted {
+			if err := unblockIfReady(ctx, transaction, d.id); err != nil {
+				return err
+			}
+			continue
+		}
+
+		reason := "dependency " + jobID.String() + " ended " + state
+
+		if d.failurePolicy == WorkflowFailDependents {
+			if err := transitionJobState(ctx, transaction, d.id, JobFailed, JobBlocked, reason); err != nil {
+				return err
+			}
+
+			if _, err := transaction.Exec(
+				ctx,
+				`
+				UPDATE jobs
+				SET last_error = $2,
+					retryable = false
+				WHERE id = $1
+				`,
+				d.id,
+				reason,
+			); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := transitionJobState(ctx, transaction, d.id, JobCancelled, JobBlocked, reason); err != nil {
+			return err
+		}
+
+		if _, err := transaction.Exec(
+			ctx,
+			`UPDATE jobs SET cancelled_at = now() WHERE id = $1`,
+			d.id,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func unblockIfReady(
+	ctx context.Context,
+	transaction pgx.Tx,
+	jobID uuid.UUID,
+) error {
+	var remaining int
+
+	if err := transaction.QueryRow(
+		ctx,
+		`
+		SELECT COUNT(*)
+		FROM job_dependencies d
+		JOIN jobs p ON p.id = d.depends_on_job_id
+		WHERE d.job_id = $1
+		  AND p.state <> 'COMPLETED'
+		`,
+		jobID,
+	).Scan(&remaining); err != nil {
+		return err
+	}
+
+	if remaining > 0 {
+		return nil
+	}
+
+	if err := transitionJobState(ctx, transaction, jobID, JobPending, JobBlocked, "dependencies completed"); err != nil {
+		return err
+	}
+
+	_, err := transaction.Exec(
+		ctx,
+		`
+		UPDATE jobs
+		SET next_run_at = GREATEST(now(), COALESCE(run_at, now())),
+			aged_priority = CASE WHEN run_at > now() THEN NULL ELSE priority END
+		WHERE id = $1
+		`,
+		jobID,
+	)
+
+	return err
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func createTestWorkflow(t *testing.T, store *Store, failurePolicy string) (uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	parentID := uuid.New()
+	childID := uuid.New()
+
+	err := store.CreateWorkflow(context.Background(), Workflow{
+		ID:            uuid.New(),
+		Name:          "test",
+		FailurePolicy: failurePolicy,
+	}, []WorkflowJob{
+		{Spec: newTestJobSpec(parentID)},
+		{Spec: newTestJobSpec(childID), DependsOn: []uuid.UUID{parentID}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parentID, childID
+}
+
+func assertJobState(t *testing.T, store *Store, jobID uuid.UUID, expected string) {
+	t.Helper()
+
+	job, err := store.GetJobByID(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.State != expected {
+		t.Fatalf("expected %s, got %s", expected, job.State)
+	}
+}
+
+func TestCompletedParentUnblocksDependent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	parentID, childID := createTestWorkflow(t, store, WorkflowCancelDependents)
+	assertJobState(t, store, childID, JobBlocked)
+
+	err := store.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err := transitionJobState(ctx, tx, parentID, JobScheduled, JobPending, ""); err != nil {
+			return err
+		}
+		if err := transitionJobState(ctx, tx, parentID, JobRunning, JobScheduled, ""); err != nil {
+			return err
+		}
+		return transitionJobState(ctx, tx, parentID, JobCompleted, JobRunning, "")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertJobState(t, store, childID, JobPending)
+}
+
+func TestCancelledParentFailsDependent(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+
+	parentID, childID := createTestWorkflow(t, store, WorkflowFailDependents)
+
+	if err := store.CancelJob(ctx, parentID); err != nil {
+		t.Fatal(err)
+	}
+
+	assertJobState(t, store, childID, JobFailed)
+}
+
+func TestWorkflowCycleRejected(t *testing.T) {
+	first := uuid.New()
+	second := uuid.New()
+
+	err := validateWorkflowGraph([]WorkflowJob{
+		{Spec: JobSpec{ID: first}, DependsOn: []uuid.UUID{second}},
+		{Spec: JobSpec{ID: second}, DependsOn: []uuid.UUID{first}},
+	})
+	if !errors.Is(err, ErrWorkflowCycle) {
+		t.Fatalf("expected ErrWorkflowCycle, got %v", err)
+	}
+
+	err = validateWorkflowGraph([]WorkflowJob{
+		{Spec: JobSpec{ID: first}},
+		{Spec: JobSpec{ID: second}, DependsOn: []uuid.UUID{uuid.New()}},
+	})
+	if !errors.Is(err, ErrWorkflowUnknownDependency) {
+		t.Fatalf("expected ErrWorkflowUnknownDependency, got %v", err)
+	}
+}
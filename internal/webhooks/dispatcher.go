@@ -0,0 +1,243 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// Headers set on every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook's secret, prefixed with
+// "sha256=".
+const (
+	SignatureHeader  = "X-Webhook-Signature"
+	TimestampHeader  = "X-Webhook-Timestamp"
+	DeliveryIDHeader = "X-Webhook-Delivery-Id"
+	EventIDHeader    = "X-Webhook-Event-Id"
+)
+
+const (
+	// MaxDeliveryAttempts is how many times a delivery is sent before it is
+	// marked FAILED.
+	MaxDeliveryAttempts = 10
+
+	// deliveryBatchSize bounds how many deliveries a dispatch pass claims.
+	deliveryBatchSize = 50
+
+	// deliveryTimeout bounds a single HTTP attempt.
+	deliveryTimeout = 10 * time.Second
+
+	// claimDuration is how long a claimed delivery is hidden from other
+	// dispatchers. It outlasts deliveryTimeout so that only a crashed
+	// dispatcher's deliveries are claimed again.
+	claimDuration = time.Minute
+
+	// maxRecordedResponse bounds how much of a failed response body is kept
+	// as the delivery's last error.
+	maxRecordedResponse = 512
+
+	// DefaultDeliveryRetention is how long delivered and failed deliveries
+	// are kept in a webhook's delivery log.
+	DefaultDeliveryRetention = 7 * 24 * time.Hour
+
+	// purgeInterval is how often finished deliveries past their retention
+	// are removed.
+	purgeInterval = time.Minute
+)
+
+// deliveryRetryPolicy spaces out the attempts of a failing delivery.
+var deliveryRetryPolicy = store.RetryPolicy{
+	InitialDelay: 5 * time.Second,
+	Multiplier:   2,
+	MaxDelay:     time.Hour,
+	Jitter:       0.1,
+}
+
+// Sign returns the signature of body sent at timestamp, as carried in
+// SignatureHeader. Receivers recompute it to authenticate a delivery.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Config tunes a Dispatcher. Zero values select the defaults.
+type Config struct {
+	// AllowedNetworks are internal networks that deliveries may reach
+	// regardless of TargetGuard.
+	AllowedNetworks []netip.Prefix
+
+	// DeliveryRetention is how long finished deliveries are kept.
+	DeliveryRetention time.Duration
+}
+
+// Dispatcher sends queued webhook deliveries. Any number of dispatchers may
+// run against the same database.
+type Dispatcher struct {
+	store  *store.Store
+	client *http.Client
+	logger *slog.Logger
+	config Config
+}
+
+func NewDispatcher(storeLayer *store.Store, logger *slog.Logger, config Config) *Dispatcher {
+	if config.DeliveryRetention <= 0 {
+		config.DeliveryRetention = DefaultDeliveryRetention
+	}
+
+	guard := NewTargetGuard(config.AllowedNetworks)
+
+	// Deliveries connect directly, never through a proxy, so that every
+	// address they reach, including after a redirect, passes the guard.
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: deliveryTimeout,
+			Control: guard.control,
+		}).DialContext,
+		ForceAttemptHTTP2:   true,
+		TLSHandshakeTimeout: deliveryTimeout,
+	}
+
+	return &Dispatcher{
+		store:  storeLayer,
+		client: &http.Client{Timeout: deliveryTimeout, Transport: transport},
+		logger: logger,
+		config: config,
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	purgeTicker := time.NewTicker(purgeInterval)
+	defer ticker.Stop()
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatchOnce(ctx)
+		case <-purgeTicker.C:
+			purged, err := d.store.PurgeFinishedWebhookDeliveries(ctx, time.Now().Add(-d.config.DeliveryRetention))
+			if err != nil {
+				d.logger.Error("webhook delivery purge failed", "error", err)
+			} else if purged > 0 {
+				d.logger.Info("finished webhook deliveries purged", "count", purged)
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchOnce(ctx context.Context) {
+	deliveries, err := d.store.ClaimDueWebhookDeliveries(ctx, deliveryBatchSize, claimDuration)
+	if err != nil {
+		d.logger.Error("claiming webhook deliveries failed", "error", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for _, delivery := range deliveries {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery store.DueWebhookDelivery) {
+	statusCode, err := d.send(ctx, delivery)
+
+	var (
+		code       *int
+		errMessage *string
+		retryAt    *time.Time
+	)
+
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	delivered := err == nil
+	if !delivered {
+		message := err.Error()
+		errMessage = &message
+		retryAt = nextDeliveryAttempt(delivery.Attempts+1, time.Now())
+	}
+
+	if err := d.store.RecordWebhookDeliveryAttempt(ctx, delivery.ID, delivered, code, errMessage, retryAt); err != nil {
+		d.logger.Error("recording webhook delivery failed", "delivery_id", delivery.ID.String(), "error", err)
+		return
+	}
+
+	if delivered {
+		d.logger.Info("webhook delivered", "delivery_id", delivery.ID.String(), "status", statusCode)
+	} else {
+		d.logger.Warn("webhook delivery failed", "delivery_id", delivery.ID.String(), "error", *errMessage, "will_retry", retryAt != nil)
+	}
+}
+
+// nextDeliveryAttempt returns when to send a delivery again after its
+// attempt-th attempt failed, or nil when it has run out of attempts and is
+// to be marked FAILED.
+func nextDeliveryAttempt(attempt int, now time.Time) *time.Time {
+	if attempt >= MaxDeliveryAttempts {
+		return nil
+	}
+
+	next := now.Add(deliveryRetryPolicy.Backoff(attempt))
+	return &next
+}
+
+// send POSTs the delivery and returns the response status, if any. Any
+// status outside 2xx counts as a failure.
+func (d *Dispatcher) send(ctx context.Context, delivery store.DueWebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(TimestampHeader, timestamp)
+	request.Header.Set(SignatureHeader, Sign(delivery.Secret, timestamp, delivery.Payload))
+	request.Header.Set(DeliveryIDHeader, delivery.ID.String())
+	request.Header.Set(EventIDHeader, strconv.FormatInt(delivery.EventID, 10))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, response.Body)
+		return response.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, maxRecordedResponse))
+
+	return response.StatusCode, fmt.Errorf("unexpected status %d: %s", response.StatusCode, body)
+}
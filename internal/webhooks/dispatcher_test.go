@@ -0,0 +1,43 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignMatchesKnownVector(t *testing.T) {
+	signature := Sign("whsec_test", "1700000000", []byte(`{"event_id":1}`))
+
+	expected := "sha256=115402565fc7b710e75917d6a369828046e151bd5816426329c59ba9f11ea916"
+	if signature != expected {
+		t.Fatalf("expected %s, got %s", expected, signature)
+	}
+}
+
+func TestDeliveryBackoffGrowsWithinJitter(t *testing.T) {
+	now := time.Now()
+
+	for attempt := 1; attempt < MaxDeliveryAttempts; attempt++ {
+		retryAt := nextDeliveryAttempt(attempt, now)
+		if retryAt == nil {
+			t.Fatalf("expected attempt %d to be retried", attempt)
+		}
+
+		base := min(
+			deliveryRetryPolicy.InitialDelay<<(attempt-1),
+			deliveryRetryPolicy.MaxDelay,
+		)
+		spread := time.Duration(float64(base) * deliveryRetryPolicy.Jitter)
+
+		delay := retryAt.Sub(now)
+		if delay < base-spread || delay > min(base+spread, deliveryRetryPolicy.MaxDelay) {
+			t.Fatalf("attempt %d: expected a delay of %s ± %s, got %s", attempt, base, spread, delay)
+		}
+	}
+}
+
+func TestDeliveryFailsAfterMaxAttempts(t *testing.T) {
+	if retryAt := nextDeliveryAttempt(MaxDeliveryAttempts, time.Now()); retryAt != nil {
+		t.Fatalf("expected the last attempt not to be retried, got a retry at %s", retryAt)
+	}
+}
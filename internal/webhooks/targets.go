@@ -0,0 +1,133 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+// forbiddenNetworks are special-purpose ranges that the netip predicates
+// used by TargetGuard.CheckAddr do not cover.
+var forbiddenNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// TargetGuard keeps webhooks from reaching the orchestrator's own network.
+// Loopback, private, link-local (including the 169.254.169.254 metadata
+// endpoint) and other special-purpose addresses are refused unless they
+// fall within an allowed network.
+type TargetGuard struct {
+	allowed []netip.Prefix
+}
+
+func NewTargetGuard(allowed []netip.Prefix) *TargetGuard {
+	return &TargetGuard{allowed: allowed}
+}
+
+// ParseAllowedNetworks parses a comma-separated list of CIDR prefixes, such
+// as the WEBHOOK_ALLOWED_NETWORKS setting.
+func ParseAllowedNetworks(raw string) ([]netip.Prefix, error) {
+	var allowed []netip.Prefix
+
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed = append(allowed, prefix.Masked())
+	}
+
+	return allowed, nil
+}
+
+// CheckAddr reports ErrForbiddenTarget when addr may not be sent to.
+func (g *TargetGuard) CheckAddr(addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if addr.IsLoopback() ||
+		addr.IsPrivate() ||
+		addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() {
+		return ErrForbiddenTarget
+	}
+
+	for _, prefix := range forbiddenNetworks {
+		if prefix.Contains(addr) {
+			return ErrForbiddenTarget
+		}
+	}
+
+	return nil
+}
+
+// CheckURL resolves the host of rawURL and reports ErrForbiddenTarget when
+// any of its addresses may not be sent to. Deliveries are checked again when
+// they connect, since the host may resolve differently by then.
+func (g *TargetGuard) CheckURL(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := target.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.CheckAddr(addr)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("resolving webhook host: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if err := g.CheckAddr(addr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// control refuses connections to forbidden addresses. It runs after the
+// host is resolved, so a host that resolves to an internal address after it
+// was registered is refused as well.
+func (g *TargetGuard) control(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckAddr(addrPort.Addr())
+}
@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestTargetGuardRefusesInternalAddresses(t *testing.T) {
+	guard := NewTargetGuard(nil)
+
+	for _, raw := range []string{
+		"127.0.0.1",
+		"10.1.2.3",
+		"172.16.0.1",
+		"192.168.1.1",
+		"169.254.169.254",
+		"100.64.0.1",
+		"0.0.0.0",
+		"::1",
+		"fd00::1",
+		"fe80::1",
+		"::ffff:127.0.0.1",
+	} {
+		if err := guard.CheckAddr(netip.MustParseAddr(raw)); !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("%s: expected ErrForbiddenTarget, got %v", raw, err)
+		}
+	}
+
+	for _, raw := range []string{"93.184.216.34", "2606:2800:220:1::1"} {
+		if err := guard.CheckAddr(netip.MustParseAddr(raw)); err != nil {
+			t.Errorf("%s: expected a public address to be allowed, got %v", raw, err)
+		}
+	}
+}
+
+func TestTargetGuardHonoursAllowedNetworks(t *testing.T) {
+	allowed, err := ParseAllowedNetworks("10.0.0.0/8, 127.0.0.1/32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guard := NewTargetGuard(allowed)
+
+	for _, raw := range []string{"10.1.2.3", "127.0.0.1"} {
+		if err := guard.CheckAddr(netip.MustParseAddr(raw)); err != nil {
+			t.Errorf("%s: expected an allowed network to pass, got %v", raw, err)
+		}
+	}
+
+	if err := guard.CheckAddr(netip.MustParseAddr("192.168.1.1")); !errors.Is(err, ErrForbiddenTarget) {
+		t.Errorf("expected an address outside the allowed networks to be refused, got %v", err)
+	}
+}
+
+func TestTargetGuardChecksLiteralURLs(t *testing.T) {
+	guard := NewTargetGuard(nil)
+
+	for _, rawURL := range []string{
+		"http://169.254.169.254/latest/meta-data",
+		"https://[::1]:8443/hooks",
+	} {
+		if err := guard.CheckURL(context.Background(), rawURL); !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("%s: expected ErrForbiddenTarget, got %v", rawURL, err)
+		}
+	}
+}
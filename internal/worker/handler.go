@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// Job is the view of a leased job that is handed to a Handler.
+type Job struct {
+	ID      uuid.UUID
+	Type    string
+	Payload []byte
+
+	// Attempt numbers the current attempt from 1, as job_attempts and the
+	// API do.
+	Attempt int
+
+	// Logger writes to the worker's log and to the job's log, which keeps
+	// the output of each attempt for GET /v1/jobs/{id}/logs.
+	Logger *slog.Logger
+
+	result         []byte
+	reportProgress func(ctx context.Context, progress store.JobProgress) error
+}
+
+// SetResult records the JSON result to store with the job when the handler
+// returns nil. A result that is not valid JSON or exceeds the worker's
+// limit fails the job permanently.
+func (j *Job) SetResult(result []byte) {
+	j.result = result
+}
+
+// ReportProgress publishes how far the job has got, replacing any earlier
+// report, and extends the job's lease. An empty stage or message is left
+// out. It does nothing for a Job that was not handed out by a Worker.
+func (j *Job) ReportProgress(ctx context.Context, percent int, stage string, message string) error {
+	if j.reportProgress == nil {
+		return nil
+	}
+
+	progress := store.JobProgress{Percent: percent}
+
+	if stage != "" {
+		progress.Stage = &stage
+	}
+
+	if message != "" {
+		progress.Message = &message
+	}
+
+	return j.reportProgress(ctx, progress)
+}
+
+// Handler executes jobs of a single type.
+//
+// Returning nil completes the job. Returning an error fails it; the failure
+// is retryable unless the error is wrapped with Permanent.
+type Handler interface {
+	Handle(ctx context.Context, job *Job) error
+}
+
+type HandlerFunc func(ctx context.Context, job *Job) error
+
+func (f HandlerFunc) Handle(ctx context.Context, job *Job) error {
+	return f(ctx, job)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as non-retryable.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+func isRetryable(err error) bool {
+	var permanent *permanentError
+	return !errors.As(err, &permanent)
+}
+
+func runHandler(ctx context.Context, handler Handler, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = Permanent(fmt.Errorf("handler panicked: %v", recovered))
+		}
+	}()
+
+	return handler.Handle(ctx, job)
+}
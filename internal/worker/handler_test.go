@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPermanentErrorsAreNotRetryable(t *testing.T) {
+	err := errors.New("bad input")
+
+	if !isRetryable(err) {
+		t.Fatal("expected a plain error to be retryable")
+	}
+
+	if isRetryable(Permanent(err)) {
+		t.Fatal("expected a permanent error not to be retryable")
+	}
+
+	if isRetryable(errors.Join(errors.New("context"), Permanent(err))) {
+		t.Fatal("expected a wrapped permanent error not to be retryable")
+	}
+
+	if !errors.Is(Permanent(err), err) {
+		t.Fatal("expected a permanent error to wrap its cause")
+	}
+
+	if Permanent(nil) != nil {
+		t.Fatal("expected Permanent(nil) to be nil")
+	}
+}
+
+func TestPanickingHandlerFailsPermanently(t *testing.T) {
+	handler := HandlerFunc(func(ctx context.Context, job *Job) error {
+		panic("boom")
+	})
+
+	err := runHandler(context.Background(), handler, &Job{})
+	if err == nil {
+		t.Fatal("expected a panicking handler to return an error")
+	}
+	if isRetryable(err) {
+		t.Fatal("expected a panic to be a permanent failure")
+	}
+	if !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the panic value in the error, got %q", err)
+	}
+}
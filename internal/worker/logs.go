@@ -0,0 +1,138 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// jobLogFlushInterval is how often buffered handler log output is written
+// to the job's log while the handler runs.
+const jobLogFlushInterval = time.Second
+
+// jobLog buffers the log output of one job attempt and writes it to the
+// store in chunks.
+type jobLog struct {
+	store     *store.Store
+	jobID     uuid.UUID
+	attempt   int
+	maxBytes  int
+	retention time.Duration
+
+	// mu guards pending and truncated.
+	mu        sync.Mutex
+	pending   bytes.Buffer
+	truncated bool
+}
+
+func (l *jobLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Output past the job's limit would be dropped by the store anyway.
+	if !l.truncated && l.pending.Len() < l.maxBytes {
+		l.pending.Write(p)
+	}
+
+	return len(p), nil
+}
+
+// flush writes the buffered output to the store. It must not run
+// concurrently with itself.
+func (l *jobLog) flush(ctx context.Context) error {
+	l.mu.Lock()
+	content := l.pending.String()
+	l.pending.Reset()
+	l.mu.Unlock()
+
+	if content == "" {
+		return nil
+	}
+
+	truncated, err := l.store.AppendJobLog(
+		ctx,
+		l.jobID,
+		l.attempt,
+		content,
+		l.maxBytes,
+		time.Now().Add(l.retention),
+	)
+	if truncated {
+		l.mu.Lock()
+		l.truncated = true
+		l.pending.Reset()
+		l.mu.Unlock()
+	}
+
+	return err
+}
+
+// run flushes the log every jobLogFlushInterval until ctx is done.
+func (l *jobLog) run(ctx context.Context, logger *slog.Logger) {
+	ticker := time.NewTicker(jobLogFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Warn("failed to write job log", "error", err)
+			}
+		}
+	}
+}
+
+// teeHandler sends every record to each of its handlers.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range t {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+
+	for _, handler := range t {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+
+		if err := handler.Handle(ctx, record.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, handler := range t {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+
+	return handlers
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	handlers := make(teeHandler, len(t))
+	for i, handler := range t {
+		handlers[i] = handler.WithGroup(name)
+	}
+
+	return handlers
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+func TestJobLogIsWrittenBeforeCompletion(t *testing.T) {
+	ctx := context.Background()
+	storeLayer := newTestStore(t)
+
+	w := newTestWorker(storeLayer)
+	w.Register("log-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		// Well within jobLogFlushInterval, so only the final flush can
+		// write this line.
+		job.Logger.Info("handled")
+		return nil
+	}))
+
+	job, lease := startTestJob(t, storeLayer, w, newTestJobSpec("log-test"))
+
+	w.executeJob(ctx, job, lease)
+
+	completed, err := storeLayer.GetJobByID(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed.State != store.JobCompleted {
+		t.Fatalf("expected the job to be completed, got %s", completed.State)
+	}
+
+	chunks, err := storeLayer.ListJobLogs(ctx, store.JobLogFilter{JobID: job.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 1 || !strings.Contains(chunks[0].Content, `"msg":"handled"`) {
+		t.Fatalf("expected the handler's output in the job log, got %+v", chunks)
+	}
+	if chunks[0].CreatedAt.After(completed.UpdatedAt) {
+		t.Fatalf(
+			"expected the log to be written before completion, written at %s, completed at %s",
+			chunks[0].CreatedAt.Format(time.RFC3339Nano),
+			completed.UpdatedAt.Format(time.RFC3339Nano),
+		)
+	}
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// testJobPriority lies far above the priority of any job other tests leave
+// behind, so that the job of a test is the one leased.
+const testJobPriority = 1_000_000_000
+
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Fatal("TEST_DATABASE_URL is required")
+	}
+
+	storeLayer, err := store.NewStore(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	t.Cleanup(storeLayer.Close)
+	return storeLayer
+}
+
+func newTestWorker(storeLayer *store.Store) *Worker {
+	return New(uuid.New(), 1, storeLayer, slog.New(slog.NewTextHandler(io.Discard, nil)), Config{})
+}
+
+// newTestJobSpec describes a job of jobType in a queue of its own that may
+// be attempted three times.
+func newTestJobSpec(jobType string) store.JobSpec {
+	return store.JobSpec{
+		ID:             uuid.New(),
+		Type:           jobType,
+		Payload:        []byte(`{}`),
+		MaxAttempts:    3,
+		TimeoutSeconds: 30,
+		Priority:       testJobPriority,
+		Queue:          "worker-" + uuid.NewString(),
+	}
+}
+
+// startTestJob creates the job of spec, registers w for its queue and hands
+// the job to w.
+func startTestJob(t *testing.T, storeLayer *store.Store, w *Worker, spec store.JobSpec) (*store.Job, *store.Lease) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	if _, err := storeLayer.CreateJob(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = storeLayer.CancelJob(context.Background(), spec.ID)
+	})
+
+	w.Subscribe(spec.Queue)
+	if err := storeLayer.RegisterWorker(ctx, w.id, w.capacity, w.subscribedQueues()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := storeLayer.AcquireJobLease(ctx, uuid.New(), store.DefaultLeaseDuration); err != nil {
+		t.Fatal(err)
+	}
+
+	job, lease, err := storeLayer.AcquireScheduledJobForWorker(ctx, w.id, []string{spec.Type}, []string{spec.Queue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID != spec.ID {
+		t.Fatalf("expected job %s to be handed out, got %s", spec.ID, job.ID)
+	}
+
+	return job, lease
+}
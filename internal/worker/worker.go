@@ -2,31 +2,122 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/vin-jex/job-orchestrator/internal/store"
 )
 
+var errLeaseLost = errors.New("job lease lost")
+
+// Config tunes a Worker. Zero values select the defaults.
+type Config struct {
+	// ResultMaxBytes caps the size of a result set by a handler, and
+	// ResultRetention is how long results are kept.
+	ResultMaxBytes  int
+	ResultRetention time.Duration
+
+	// LogMaxBytes caps the log output kept for a job across its attempts,
+	// and LogRetention is how long log output is kept.
+	LogMaxBytes  int
+	LogRetention time.Duration
+}
+
 type Worker struct {
 	id       uuid.UUID
 	capacity int
 	store    *store.Store
 	logger   *slog.Logger
+	config   Config
+
+	// mu guards handlers and queues.
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	queues   []string
 }
 
-func New(id uuid.UUID, capacity int, storeLayer *store.Store, logger *slog.Logger) *Worker {
+func New(id uuid.UUID, capacity int, storeLayer *store.Store, logger *slog.Logger, config Config) *Worker {
+	if config.ResultMaxBytes <= 0 {
+		config.ResultMaxBytes = store.DefaultJobResultMaxBytes
+	}
+
+	if config.ResultRetention <= 0 {
+		config.ResultRetention = store.DefaultJobResultRetention
+	}
+
+	if config.LogMaxBytes <= 0 {
+		config.LogMaxBytes = store.DefaultJobLogMaxBytes
+	}
+
+	if config.LogRetention <= 0 {
+		config.LogRetention = store.DefaultJobLogRetention
+	}
+
 	return &Worker{
 		id:       id,
 		capacity: capacity,
 		store:    storeLayer,
 		logger:   logger,
+		config:   config,
+		handlers: make(map[string]Handler),
 	}
 }
 
+// Register installs the handler for jobs of the given type. The worker only
+// picks up jobs whose type has a registered handler.
+func (w *Worker) Register(jobType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.handlers[jobType] = handler
+}
+
+// Subscribe restricts the worker to jobs from the given queues. A worker
+// that never subscribes takes jobs from every queue. Subscriptions are
+// registered when the worker starts, so Subscribe must be called before Run.
+func (w *Worker) Subscribe(queues ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.queues = append(w.queues, queues...)
+}
+
+func (w *Worker) subscribedQueues() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]string(nil), w.queues...)
+}
+
+func (w *Worker) handler(jobType string) (Handler, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	handler, ok := w.handlers[jobType]
+	return handler, ok
+}
+
+func (w *Worker) jobTypes() []string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	jobTypes := make([]string, 0, len(w.handlers))
+	for jobType := range w.handlers {
+		jobTypes = append(jobTypes, jobType)
+	}
+
+	return jobTypes
+}
+
 func (w *Worker) Run(ctx context.Context) error {
-	if err := w.store.RegisterWorker(ctx, w.id, w.capacity); err != nil {
+	ctx = store.WithActor(ctx, "worker:"+w.id.String())
+
+	if err := w.store.RegisterWorker(ctx, w.id, w.capacity, w.subscribedQueues()); err != nil {
 		return err
 	}
 
@@ -59,64 +150,200 @@ func (w *Worker) runExecutor(ctx context.Context) {
 			go func() {
 				defer func() { <-semaphore }()
 
-				jobID, payload, err := w.store.AcquireScheduledJobForWorker(ctx, w.id)
+				job, lease, err := w.store.AcquireScheduledJobForWorker(ctx, w.id, w.jobTypes(), w.subscribedQueues())
 				if err != nil {
 					time.Sleep(300 * time.Millisecond)
 					return
 				}
-				w.logger.Info("job picked up", "job_id", jobID.String(), "worker_id", w.id.String())
-
-				jobCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-				defer cancel()
-
-				cancelWatcherDone := make(chan struct{})
-
-				go func() {
-					defer close(cancelWatcherDone)
-
-					ticker := time.NewTicker(500 * time.Millisecond)
-					defer ticker.Stop()
-
-					for {
-						select {
-						case <-jobCtx.Done():
-							return
-						case <-ticker.C:
-							cancelled, err := w.store.IsJobCancelled(ctx, jobID)
-							if err != nil {
-								continue
-							}
-							if cancelled {
-								cancel()
-								w.logger.Info("job cancelled during execution", "job_id", jobID.String(), "worker_id", w.id.String())
-								return
-							}
-						}
-					}
-				}()
-
-				select {
-				case <-jobCtx.Done():
-					return
-				case <-time.After(1 * time.Second):
-				default:
-				}
+				w.logger.Info("job picked up", "job_id", job.ID.String(), "job_type", job.Type, "queue", job.Queue, "worker_id", w.id.String())
+
+				w.executeJob(ctx, job, lease)
+			}()
+		}
+	}
+}
+
+func (w *Worker) executeJob(ctx context.Context, job *store.Job, lease *store.Lease) {
+	logger := w.logger.With("job_id", job.ID.String(), "job_type", job.Type, "worker_id", w.id.String())
+
+	handler, ok := w.handler(job.Type)
+	if !ok {
+		if _, err := w.store.FailJob(ctx, job.ID, lease.FencingToken, "no handler registered for job type "+job.Type, false); err != nil {
+			logger.Error("failed to record job failure", "error", err)
+		}
+		return
+	}
+
+	timeout := time.Duration(job.TimeoutSeconds) * time.Second
+
+	leaseCtx, cancelLease := context.WithCancelCause(ctx)
+	defer cancelLease(nil)
+
+	jobCtx, cancel := context.WithTimeout(leaseCtx, timeout)
+	defer cancel()
 
-				_ = payload
+	renewCtx, stopRenewal := context.WithCancel(jobCtx)
+	defer stopRenewal()
 
-				time.Sleep(1 * time.Second)
+	go w.renewLease(renewCtx, cancelLease, lease, logger)
 
-				cancelled, err := w.store.IsJobCancelled(ctx, jobID)
-				if err == nil && cancelled {
-					w.logger.Info("job cancelled during execution", "job_id", jobID.String(), "worker_id", w.id.String())
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+				cancelled, err := w.store.IsJobCancelled(ctx, job.ID)
+				if err != nil {
+					continue
+				}
+				if cancelled {
+					cancel()
+					logger.Info("job cancelled during execution")
 					return
 				}
+			}
+		}
+	}()
+
+	attempt := job.CurrentAttempt + 1
 
-				_ = w.store.MarkJobCompleted(jobCtx, jobID)
+	jobLog := &jobLog{
+		store:     w.store,
+		jobID:     job.ID,
+		attempt:   attempt,
+		maxBytes:  w.config.LogMaxBytes,
+		retention: w.config.LogRetention,
+	}
 
-				w.logger.Info("job completed", "job_id", jobID.String(), "worker_id", w.id.String())
+	flushCtx, stopFlushing := context.WithCancel(ctx)
+	defer stopFlushing()
 
-			}()
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		jobLog.run(flushCtx, logger)
+	}()
+
+	handlerJob := &Job{
+		ID:      job.ID,
+		Type:    job.Type,
+		Payload: job.Payload,
+		Attempt: attempt,
+		Logger: slog.New(teeHandler{
+			logger.Handler(),
+			slog.NewJSONHandler(jobLog, &slog.HandlerOptions{}),
+		}),
+		reportProgress: func(ctx context.Context, progress store.JobProgress) error {
+			_, err := w.store.ReportJobProgress(
+				ctx,
+				job.ID,
+				lease.FencingToken,
+				progress,
+				store.DefaultLeaseDuration,
+			)
+			return err
+		},
+	}
+
+	handlerErr := runHandler(jobCtx, handler, handlerJob)
+	stopRenewal()
+
+	// The rest of the log is written before the job's outcome is recorded,
+	// so a job that has finished has its complete log.
+	stopFlushing()
+	<-flushed
+	if err := jobLog.flush(ctx); err != nil {
+		logger.Warn("failed to write job log", "error", err)
+	}
+
+	cancelled, err := w.store.IsJobCancelled(ctx, job.ID)
+	if err == nil && cancelled {
+		logger.Info("job cancelled during execution")
+		return
+	}
+
+	if errors.Is(context.Cause(leaseCtx), errLeaseLost) {
+		logger.Warn("job lease lost during execution")
+		return
+	}
+
+	if errors.Is(jobCtx.Err(), context.DeadlineExceeded) {
+		handlerErr = fmt.Errorf("job exceeded timeout of %s", timeout)
+	}
+
+	var result *store.JobResult
+
+	if handlerErr == nil && handlerJob.result != nil {
+		switch {
+		case !json.Valid(handlerJob.result):
+			handlerErr = Permanent(errors.New("job result is not valid JSON"))
+		case len(handlerJob.result) > w.config.ResultMaxBytes:
+			handlerErr = Permanent(fmt.Errorf(
+				"job result of %d bytes exceeds the limit of %d bytes",
+				len(handlerJob.result),
+				w.config.ResultMaxBytes,
+			))
+		default:
+			result = &store.JobResult{
+				Result:    handlerJob.result,
+				ExpiresAt: time.Now().Add(w.config.ResultRetention),
+			}
+		}
+	}
+
+	if handlerErr != nil {
+		if _, err := w.store.FailJob(ctx, job.ID, lease.FencingToken, handlerErr.Error(), isRetryable(handlerErr)); err != nil {
+			logger.Error("failed to record job failure", "error", err)
+			return
+		}
+
+		logger.Info("job failed", "error", handlerErr)
+		return
+	}
+
+	if err := w.store.CompleteJob(ctx, job.ID, lease.FencingToken, result); err != nil {
+		logger.Error("failed to record job completion", "error", err)
+		return
+	}
+
+	logger.Info("job completed")
+}
+
+// renewLease keeps the job's lease alive until ctx is done. If the lease can
+// no longer be renewed, the job is cancelled with errLeaseLost.
+func (w *Worker) renewLease(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	lease *store.Lease,
+	logger *slog.Logger,
+) {
+	ticker := time.NewTicker(store.DefaultLeaseDuration / 3)
+	defer ticker.Stop()
+
+	for {
+		_, err := w.store.RenewLease(
+			ctx,
+			lease.JobID,
+			lease.HolderID,
+			lease.FencingToken,
+			store.DefaultLeaseDuration,
+		)
+		if errors.Is(err, store.ErrLeaseNotHeld) {
+			cancel(errLeaseLost)
+			return
+		}
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("lease renewal failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
 	}
 }
@@ -0,0 +1,149 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// assertJobState fails the test unless the job is in state, and returns it.
+func assertJobState(t *testing.T, storeLayer *store.Store, jobID uuid.UUID, state string) *store.Job {
+	t.Helper()
+
+	job, err := storeLayer.GetJobByID(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.State != state {
+		t.Fatalf("expected job %s to be %s, got %s", jobID, state, job.State)
+	}
+
+	return job
+}
+
+func TestHandlerReceivesJob(t *testing.T) {
+	ctx := context.Background()
+	storeLayer := newTestStore(t)
+
+	var handled *Job
+
+	w := newTestWorker(storeLayer)
+	w.Register("dispatch-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		handled = job
+		return nil
+	}))
+
+	spec := newTestJobSpec("dispatch-test")
+	spec.Payload = []byte(`{"n":1}`)
+
+	job, lease := startTestJob(t, storeLayer, w, spec)
+	w.executeJob(ctx, job, lease)
+
+	if handled == nil {
+		t.Fatal("expected the registered handler to run")
+	}
+	if handled.ID != spec.ID || handled.Type != spec.Type || string(handled.Payload) != `{"n":1}` {
+		t.Fatalf("unexpected job handed to the handler: %+v", handled)
+	}
+	if handled.Attempt != 1 {
+		t.Fatalf("expected the first attempt to be numbered 1, got %d", handled.Attempt)
+	}
+
+	assertJobState(t, storeLayer, spec.ID, store.JobCompleted)
+}
+
+func TestUnknownJobTypeFailsPermanently(t *testing.T) {
+	storeLayer := newTestStore(t)
+	w := newTestWorker(storeLayer)
+
+	spec := newTestJobSpec("unknown-" + uuid.NewString())
+
+	job, lease := startTestJob(t, storeLayer, w, spec)
+	w.executeJob(context.Background(), job, lease)
+
+	failed := assertJobState(t, storeLayer, spec.ID, store.JobFailed)
+	if failed.LastError == nil || !strings.Contains(*failed.LastError, "no handler registered") {
+		t.Fatalf("unexpected last error: %v", failed.LastError)
+	}
+}
+
+func TestHandlerErrorsAreRetriedUnlessPermanent(t *testing.T) {
+	storeLayer := newTestStore(t)
+
+	w := newTestWorker(storeLayer)
+	w.Register("retryable-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		return errors.New("temporarily unavailable")
+	}))
+	w.Register("permanent-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		return Permanent(errors.New("bad input"))
+	}))
+
+	retryableSpec := newTestJobSpec("retryable-test")
+	job, lease := startTestJob(t, storeLayer, w, retryableSpec)
+	w.executeJob(context.Background(), job, lease)
+
+	retried := assertJobState(t, storeLayer, retryableSpec.ID, store.JobPending)
+	if retried.CurrentAttempt != 1 {
+		t.Fatalf("expected a retry to count the failed attempt, got %d", retried.CurrentAttempt)
+	}
+
+	permanentSpec := newTestJobSpec("permanent-test")
+	job, lease = startTestJob(t, storeLayer, w, permanentSpec)
+	w.executeJob(context.Background(), job, lease)
+
+	failed := assertJobState(t, storeLayer, permanentSpec.ID, store.JobFailed)
+	if failed.LastError == nil || *failed.LastError != "bad input" {
+		t.Fatalf("unexpected last error: %v", failed.LastError)
+	}
+}
+
+func TestPanickingHandlerFailsJobPermanently(t *testing.T) {
+	storeLayer := newTestStore(t)
+
+	w := newTestWorker(storeLayer)
+	w.Register("panic-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		panic("boom")
+	}))
+
+	spec := newTestJobSpec("panic-test")
+
+	job, lease := startTestJob(t, storeLayer, w, spec)
+	w.executeJob(context.Background(), job, lease)
+
+	failed := assertJobState(t, storeLayer, spec.ID, store.JobFailed)
+	if failed.LastError == nil || !strings.Contains(*failed.LastError, "handler panicked") {
+		t.Fatalf("unexpected last error: %v", failed.LastError)
+	}
+}
+
+func TestHandlerPastDeadlineIsCancelledAndFailed(t *testing.T) {
+	storeLayer := newTestStore(t)
+
+	var handlerErr error
+
+	w := newTestWorker(storeLayer)
+	w.Register("timeout-test", HandlerFunc(func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		handlerErr = ctx.Err()
+		return handlerErr
+	}))
+
+	spec := newTestJobSpec("timeout-test")
+	spec.TimeoutSeconds = 1
+
+	job, lease := startTestJob(t, storeLayer, w, spec)
+	w.executeJob(context.Background(), job, lease)
+
+	if !errors.Is(handlerErr, context.DeadlineExceeded) {
+		t.Fatalf("expected the handler's context to pass its deadline, got %v", handlerErr)
+	}
+
+	retried := assertJobState(t, storeLayer, spec.ID, store.JobPending)
+	if retried.LastError == nil || !strings.Contains(*retried.LastError, "exceeded timeout of 1s") {
+		t.Fatalf("unexpected last error: %v", retried.LastError)
+	}
+}
@@ -0,0 +1,124 @@
+// Package enqueue creates orchestrator jobs from inside a caller's own
+// Postgres transaction, for services that share the orchestrator's
+// database. A job enqueued with EnqueueTx exists if and only if the
+// surrounding transaction commits, so business writes and the jobs they
+// trigger can never disagree.
+package enqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/vin-jex/job-orchestrator/internal/store"
+)
+
+// Conflict policies for JobSpec.OnConflict.
+const (
+	OnConflictReject         = store.UniqueConflictReject
+	OnConflictReturnExisting = store.UniqueConflictReturnExisting
+	OnConflictReplace        = store.UniqueConflictReplace
+)
+
+var (
+	ErrInvalidJobSpec = errors.New("invalid job spec")
+
+	// ErrUniqueJobExists is returned when an unfinished job already holds
+	// the spec's UniqueKey under the OnConflictReject policy.
+	ErrUniqueJobExists = store.ErrUniqueJobExists
+)
+
+// RetryPolicy controls the delay between attempts. The zero value selects
+// the orchestrator's default policy.
+type RetryPolicy struct {
+	InitialDelay time.Duration
+	Multiplier   float64
+	MaxDelay     time.Duration
+	Jitter       float64
+}
+
+// JobSpec describes a job to enqueue, with the same meaning as the fields of
+// POST /v1/jobs. A zero ID is replaced with a random one, a nil Payload
+// means an empty JSON object, and a nil RunAt makes the job eligible
+// immediately.
+type JobSpec struct {
+	ID               uuid.UUID
+	Type             string
+	Payload          []byte
+	MaxAttempts      int
+	TimeoutSeconds   int
+	RetryPolicy      RetryPolicy
+	RunAt            *time.Time
+	Priority         int
+	Queue            string
+	UniqueKey        string
+	OnConflict       string
+	ConcurrencyKey   string
+	ConcurrencyLimit int
+}
+
+// EnqueueTx creates a PENDING job inside tx and returns the ID of the job to
+// track. That is spec.ID unless spec.UniqueKey matched an existing job under
+// OnConflictReturnExisting. An invalid spec is rejected with
+// ErrInvalidJobSpec before tx is used, so the transaction stays usable.
+func EnqueueTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	spec JobSpec,
+) (uuid.UUID, error) {
+	if spec.Type == "" ||
+		spec.MaxAttempts < 1 ||
+		spec.TimeoutSeconds <= 0 ||
+		spec.ConcurrencyLimit < 0 ||
+		(spec.ConcurrencyLimit > 0 && spec.ConcurrencyKey == "") {
+		return uuid.Nil, ErrInvalidJobSpec
+	}
+
+	if spec.Payload == nil {
+		spec.Payload = []byte(`{}`)
+	}
+
+	if !json.Valid(spec.Payload) {
+		return uuid.Nil, ErrInvalidJobSpec
+	}
+
+	switch spec.OnConflict {
+	case "", OnConflictReject, OnConflictReturnExisting, OnConflictReplace:
+	default:
+		return uuid.Nil, ErrInvalidJobSpec
+	}
+
+	if spec.ID == uuid.Nil {
+		spec.ID = uuid.New()
+	}
+
+	return store.CreateJobTx(ctx, tx, store.JobSpec{
+		ID:             spec.ID,
+		Type:           spec.Type,
+		Payload:        spec.Payload,
+		MaxAttempts:    spec.MaxAttempts,
+		TimeoutSeconds: spec.TimeoutSeconds,
+		RetryPolicy: store.RetryPolicy{
+			InitialDelay: spec.RetryPolicy.InitialDelay,
+			Multiplier:   spec.RetryPolicy.Multiplier,
+			MaxDelay:     spec.RetryPolicy.MaxDelay,
+			Jitter:       spec.RetryPolicy.Jitter,
+		},
+		RunAt:            spec.RunAt,
+		Priority:         spec.Priority,
+		Queue:            spec.Queue,
+		UniqueKey:        spec.UniqueKey,
+		OnConflict:       spec.OnConflict,
+		ConcurrencyKey:   spec.ConcurrencyKey,
+		ConcurrencyLimit: spec.ConcurrencyLimit,
+	})
+}
+
+// WithActor attributes jobs enqueued with the returned context to actor in
+// the job audit log, e.g. "billing-service".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return store.WithActor(ctx, actor)
+}
@@ -0,0 +1,151 @@
+package enqueue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Fatal("TEST_DATABASE_URL is required")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func newTestSpec() JobSpec {
+	return JobSpec{
+		ID:             uuid.New(),
+		Type:           "test",
+		MaxAttempts:    3,
+		TimeoutSeconds: 30,
+	}
+}
+
+// jobState returns the state of a job, or "" when it does not exist.
+func jobState(t *testing.T, pool *pgxpool.Pool, jobID uuid.UUID) string {
+	t.Helper()
+
+	var state string
+
+	err := pool.QueryRow(context.Background(), `SELECT state FROM jobs WHERE id = $1`, jobID).Scan(&state)
+	if err == pgx.ErrNoRows {
+		return ""
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return state
+}
+
+func TestEnqueueTxCommitCreatesJob(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	spec := newTestSpec()
+
+	jobID, err := EnqueueTx(ctx, tx, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jobID != spec.ID {
+		t.Fatalf("expected job %s, got %s", spec.ID, jobID)
+	}
+
+	if state := jobState(t, pool, jobID); state != "" {
+		t.Fatalf("expected the job to be invisible before commit, got %s", state)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := jobState(t, pool, jobID); state != "PENDING" {
+		t.Fatalf("expected a PENDING job after commit, got %q", state)
+	}
+}
+
+func TestEnqueueTxRollbackLeavesNoJob(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jobID, err := EnqueueTx(ctx, tx, newTestSpec())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := jobState(t, pool, jobID); state != "" {
+		t.Fatalf("expected no job after rollback, got %s", state)
+	}
+}
+
+func TestEnqueueTxInvalidSpecLeavesTxUsable(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPool(t)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	invalidPayload := newTestSpec()
+	invalidPayload.Payload = []byte(`{not json`)
+
+	negativeLimit := newTestSpec()
+	negativeLimit.ConcurrencyKey = "account"
+	negativeLimit.ConcurrencyLimit = -1
+
+	limitWithoutKey := newTestSpec()
+	limitWithoutKey.ConcurrencyLimit = 2
+
+	for _, spec := range []JobSpec{invalidPayload, negativeLimit, limitWithoutKey} {
+		if _, err := EnqueueTx(ctx, tx, spec); !errors.Is(err, ErrInvalidJobSpec) {
+			t.Fatalf("expected ErrInvalidJobSpec, got %v", err)
+		}
+	}
+
+	jobID, err := EnqueueTx(ctx, tx, newTestSpec())
+	if err != nil {
+		t.Fatalf("expected the transaction to stay usable, got %v", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if state := jobState(t, pool, jobID); state != "PENDING" {
+		t.Fatalf("expected a PENDING job after commit, got %q", state)
+	}
+}